@@ -0,0 +1,44 @@
+package main_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewActivityProfile(t *testing.T) {
+	monday := time.Date(2021, 1, 18, 0, 0, 0, 0, time.UTC).Unix()
+	hour := int64(60 * 60)
+	commits := []types.Commit{
+		{Timestamp: monday + 10*hour},     // monday 10:00
+		{Timestamp: monday + 10*hour + 1}, // monday 10:00
+		{Timestamp: monday + 20*hour},     // monday 20:00, off-hours
+		{Timestamp: monday + 5*24*hour},   // saturday 00:00, weekend
+	}
+
+	p := types.NewActivityProfile(commits, time.UTC)
+	if p.Heatmap[time.Monday][10] != 2 || p.Heatmap[time.Monday][20] != 1 || p.Heatmap[time.Saturday][0] != 1 {
+		t.Errorf("NewActivityProfile() heatmap = %v", p.Heatmap)
+	}
+	if diff := cmp.Diff([]float64{0.25, 0.25}, []float64{p.WeekendShare, p.OffHoursShare}); diff != "" {
+		t.Errorf("NewActivityProfile() shares mismatch (-want +got):\n%s", diff)
+	}
+
+	// 10:00 UTC is 19:00 in Tokyo, 20:00 UTC is 05:00 on tuesday
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = types.NewActivityProfile(commits, tokyo)
+	if p.Heatmap[time.Monday][19] != 2 || p.Heatmap[time.Tuesday][5] != 1 || p.OffHoursShare != 0.75 {
+		t.Errorf("NewActivityProfile(Asia/Tokyo) = %+v", p)
+	}
+
+	lines := strings.Split(types.NewActivityProfile(commits, time.UTC).String(), "\n")
+	if !strings.HasPrefix(lines[2], "Mon  ") || lines[2][5+10] != '@' || lines[2][5+20] == ' ' {
+		t.Errorf("String() monday row = %q", lines[2])
+	}
+}
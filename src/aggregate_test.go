@@ -0,0 +1,47 @@
+package main_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/FliCrz/blipper/src/utils"
+)
+
+func TestAggregateByRepository(t *testing.T) {
+	commits := syntheticCommits(5000, 30)
+	repoMap := utils.GroupByRepository(commits, false)
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			aggregates := utils.AggregateByRepository(commits, workers, 0, false)
+			if len(aggregates) != len(repoMap) {
+				t.Fatalf("AggregateByRepository() got %d repositories, want %d", len(aggregates), len(repoMap))
+			}
+			for name, r := range repoMap {
+				a := aggregates[name]
+				for _, f := range []string{"timestamp", "files", "additions", "deletions", "users", "commits"} {
+					if got, want := a.ScoreByFilter(f), r.ScoreByFilter(f); got != want {
+						t.Errorf("%s: Aggregate.ScoreByFilter(%s) = %d, want %d", name, f, got, want)
+					}
+				}
+				first, last := utils.TimeRange(r.Commits)
+				if a.First != first || a.Last != last {
+					t.Errorf("%s: Aggregate first, last = %d, %d, want %d, %d", name, a.First, a.Last, first, last)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateDistinctUsers(t *testing.T) {
+	a := types.NewAggregate(0)
+	for _, u := range []string{"user1", "user2", "user1", "unknown"} {
+		a.Add(types.Commit{User: u})
+	}
+	if got := a.ScoreByFilter("distinctUsers"); got != 3 {
+		t.Errorf("ScoreByFilter(distinctUsers) = %d, want 3", got)
+	}
+	if got := a.ScoreByFilter("users"); got != 4 {
+		t.Errorf("ScoreByFilter(users) = %d, want 4", got)
+	}
+}
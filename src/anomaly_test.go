@@ -0,0 +1,93 @@
+package main_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/FliCrz/blipper/src/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	series := func(commits ...int64) (buckets []types.Bucket) {
+		for n, c := range commits {
+			buckets = append(buckets, types.Bucket{Start: int64(n), Commits: c})
+		}
+		return buckets
+	}
+
+	testCases := []struct {
+		name      string
+		buckets   []types.Bucket
+		threshold float64
+		expected  []types.Alert
+	}{
+		{
+			name:      "Steady",
+			buckets:   series(5, 5, 5, 5, 6),
+			threshold: 3,
+			expected:  nil,
+		},
+		{
+			name:      "Drop",
+			buckets:   series(5, 5, 5, 5, 0),
+			threshold: 3,
+			expected: []types.Alert{
+				{Start: 4, Kind: "drop", Commits: 0, Mean: 5, StdDev: 1, ZScore: -5, Threshold: 3},
+			},
+		},
+		{
+			name:      "Spike under a higher threshold",
+			buckets:   series(1, 3, 1, 3, 10),
+			threshold: 10,
+			expected:  nil,
+		},
+		{
+			name:      "Spike",
+			buckets:   series(1, 3, 1, 3, 10),
+			threshold: 3,
+			expected: []types.Alert{
+				{Start: 4, Kind: "spike", Commits: 10, Mean: 2, StdDev: 1, ZScore: 8, Threshold: 3},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := types.DetectAnomalies(tc.buckets, 4, tc.threshold)
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("DetectAnomalies() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPostJSON(t *testing.T) {
+	var received []types.Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("webhook body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	alerts := []types.Alert{{Repository: "repo1", Kind: "drop", ZScore: -4}}
+	utils.PostJSON(server.URL, alerts, false)
+	if diff := cmp.Diff(alerts, received); diff != "" {
+		t.Errorf("PostJSON() mismatch (-want +got):\n%s", diff)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("PostJSON() did not panic on a failing webhook")
+		}
+	}()
+	utils.PostJSON(failing.URL, alerts, false)
+}
@@ -0,0 +1,60 @@
+package main_test
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/FliCrz/blipper/src/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func countElements(t *testing.T, svg, name string) int {
+	t.Helper()
+	d := xml.NewDecoder(strings.NewReader(svg))
+	count := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if err != io.EOF {
+				t.Fatalf("invalid svg: %v\n%s", err, svg)
+			}
+			return count
+		}
+		if e, ok := tok.(xml.StartElement); ok && e.Name.Local == name {
+			count++
+		}
+	}
+}
+
+func TestCharts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		svg      string
+		element  string
+		expected int
+	}{
+		{"Stacked bars skip empty parts", utils.StackedBarChart("score", []string{"repo<1>", "repo2"}, []string{"files", "users"},
+			[][]int64{{30, 10}, {0, 5}}), "rect", 1 + 3 + 2}, // background, bars, legend
+		{"Stacked bars without score", utils.StackedBarChart("score", []string{"repo1"}, []string{"files"}, [][]int64{{0}}), "rect", 1 + 1},
+		{"One line per series", utils.LineChart("weekly", []string{"w1", "w2", "w3"}, []utils.Series{
+			{Name: "repo1", Values: []int64{1, 4, 2}}, {Name: "repo2", Values: []int64{0, 0, 0}}}), "polyline", 2},
+		{"More series than colors", utils.LineChart("weekly", []string{"w1", "w2"}, func() (series []utils.Series) {
+			for range len(utils.Colors) + 4 {
+				series = append(series, utils.Series{Name: "repo", Values: []int64{1, 2}})
+			}
+			return series
+		}()), "polyline", len(utils.Colors) + 4},
+		{"Single point line", utils.LineChart("weekly", []string{"w1"}, []utils.Series{{Name: "repo1", Values: []int64{3}}}), "polyline", 1},
+		{"Pie slices", utils.PieChart("authors", []utils.Slice{{Label: "user1", Value: 3}, {Label: "user2", Value: 1}, {Label: "user3", Value: 0}}), "path", 2},
+		{"Full pie", utils.PieChart("authors", []utils.Slice{{Label: "user1", Value: 3}}), "circle", 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, countElements(t, tc.svg, tc.element)); diff != "" {
+				t.Errorf("%s count mismatch (-want +got):\n%s", tc.element, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package main_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCohorts(t *testing.T) {
+	day := int64(24 * 60 * 60)
+	monday := int64(1610928000) // 2021-01-18
+	r := types.Repository{Commits: []types.Commit{
+		{User: "user1", Timestamp: monday},
+		{User: "user1", Timestamp: monday + 1},
+		{User: "user2", Timestamp: monday + 2*day},
+		{User: "user2", Timestamp: monday + 15*day},
+		{User: "user3", Timestamp: monday + 8*day},
+		{User: "unknown", Timestamp: monday},
+	}}
+	last := monday + 15*day
+
+	expectedCohorts := []types.Cohort{
+		{Week: monday, Size: 2, Retained: []int64{2, 0, 1}},
+		{Week: monday + 7*day, Size: 1, Retained: []int64{1, 0}},
+	}
+	if diff := cmp.Diff(expectedCohorts, r.Cohorts(last, time.UTC)); diff != "" {
+		t.Errorf("Cohorts() mismatch (-want +got):\n%s", diff)
+	}
+
+	expectedContributors := []types.Contributor{
+		{User: "user1", First: monday, Last: monday + 1, Commits: 2, ActiveWeeks: 1, Inactive: true},
+		{User: "user2", First: monday + 2*day, Last: monday + 15*day, Commits: 2, ActiveWeeks: 2},
+		{User: "user3", First: monday + 8*day, Last: monday + 8*day, Commits: 1, ActiveWeeks: 1},
+	}
+	if diff := cmp.Diff(expectedContributors, r.Contributors(last, 10*day, time.UTC)); diff != "" {
+		t.Errorf("Contributors() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,72 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestConcentration(t *testing.T) {
+	testCases := []struct {
+		name       string
+		repository types.Repository
+		by         string
+		expected   types.Concentration
+	}{
+		{
+			name: "Single author",
+			repository: types.Repository{Commits: []types.Commit{
+				{User: "user1"},
+				{User: "user1"},
+				{User: "unknown"},
+			}},
+			by:       "commits",
+			expected: types.Concentration{Authors: 1, BusFactor: 1, Gini: 1, TopAuthor: "user1", TopShare: 1},
+		},
+		{
+			name: "Evenly spread",
+			repository: types.Repository{Commits: []types.Commit{
+				{User: "user1"}, {User: "user2"}, {User: "user3"}, {User: "user4"},
+			}},
+			by:       "commits",
+			expected: types.Concentration{Authors: 4, BusFactor: 2, Gini: 0, TopAuthor: "user1", TopShare: 0.25},
+		},
+		{
+			name: "Concentrated churn",
+			repository: types.Repository{Commits: []types.Commit{
+				{User: "user1", Additions: 1},
+				{User: "user2", Additions: 1},
+				{User: "user3", Additions: 1},
+				{User: "user4", Additions: 5, Deletions: 2},
+			}},
+			by:       "churn",
+			expected: types.Concentration{Authors: 4, BusFactor: 1, Gini: 0.6, TopAuthor: "user4", TopShare: 0.7},
+		},
+		{
+			name: "Two authors, one nearly alone",
+			repository: types.Repository{Commits: []types.Commit{
+				{User: "user1", Additions: 99},
+				{User: "user2", Additions: 1},
+			}},
+			by:       "churn",
+			expected: types.Concentration{Authors: 2, BusFactor: 1, Gini: 0.98, TopAuthor: "user1", TopShare: 0.99},
+		},
+		{
+			name:       "Unknown authors only",
+			repository: types.Repository{Commits: []types.Commit{{User: "unknown"}}},
+			by:         "commits",
+			expected:   types.Concentration{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.repository.Concentration(tc.by)
+			if diff := cmp.Diff(tc.expected, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("Concentration(%s) mismatch (-want +got):\n%s", tc.by, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/FliCrz/blipper/src/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffRankings(t *testing.T) {
+	base := []types.Repository{
+		{Repository: "repo1", Score: 100},
+		{Repository: "repo2", Score: 50},
+		{Repository: "repo3", Score: 25},
+		{Repository: "repo4", Score: 10},
+	}
+	head := []types.Repository{
+		{Repository: "repo2", Score: 120},
+		{Repository: "repo1", Score: 90},
+		{Repository: "repo5", Score: 30},
+		{Repository: "repo4", Score: 10},
+	}
+	expected := []types.RankChange{
+		{Repository: "repo2", Status: "up", BaseRank: 2, HeadRank: 1, Movement: 1, BaseScore: 50, HeadScore: 120, Delta: 70},
+		{Repository: "repo1", Status: "down", BaseRank: 1, HeadRank: 2, Movement: -1, BaseScore: 100, HeadScore: 90, Delta: -10},
+		{Repository: "repo5", Status: "new", HeadRank: 3, HeadScore: 30, Delta: 30},
+		{Repository: "repo4", Status: "same", BaseRank: 4, HeadRank: 4, BaseScore: 10, HeadScore: 10},
+		{Repository: "repo3", Status: "dropped", BaseRank: 3, BaseScore: 25, Delta: -25},
+	}
+
+	got := utils.DiffRankings(base, head)
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("DiffRankings() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,141 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseMetric(t *testing.T) {
+	commits := []types.Commit{
+		{Timestamp: 100, User: "user1", Repository: "repo1", Files: 30, Additions: 10, Deletions: 5},
+		{Timestamp: 200, User: "user2", Repository: "repo1", Files: 2, Additions: 4, Deletions: 1},
+		{Timestamp: 300, User: "user1", Repository: "repo1", Files: 25, Additions: 0, Deletions: 40},
+	}
+	testCases := []struct {
+		name     string
+		source   string
+		expected int64
+	}{
+		{"Churn per commit", "sum(additions + deletions) / count()", 20},
+		{"Conditional count", "count(files > 20)", 2},
+		{"Boolean operators", `count(files > 20 and not (user == "user2" or deletions >= 40))`, 1},
+		{"Precedence", "sum(files) - 2 * count() % 4", 55},
+		{"Unary minus", "-min(additions) + max(deletions)", 40},
+		{"Average rounded", "avg(files)", 19},
+		{"Distinct values", "distinct(user) * 100 + distinct(files > 20)", 202},
+		{"Division by zero", "sum(files) / count(files > 100)", 0},
+		{"Case insensitive keywords", "count(files > 20 AND TRUE)", 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := types.ParseMetric(tc.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := types.Repository{Repository: "repo1", Commits: commits}
+			if diff := cmp.Diff(tc.expected, r.ScoreByExpression(e)); diff != "" {
+				t.Errorf("ScoreByExpression() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if e, _ := types.ParseMetric("avg(files) + count()"); e.Metric(nil) != 0 {
+		t.Errorf("expected an empty repository to score 0")
+	}
+}
+
+func TestParseMetricErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"Field outside aggregate", "files", "TypeError: field files is only available within an aggregate such as sum(files)"},
+		{"Unknown field", "sum(lines)", "TypeError: unsupported field: lines"},
+		{"Unknown function", "median(files)", "TypeError: unsupported function: median"},
+		{"Nested aggregate", "sum(count())", "TypeError: aggregate count cannot be nested in another aggregate"},
+		{"Argument kind", "sum(user)", "TypeError: sum takes a number, not a string"},
+		{"Count of numbers", "count(files)", "TypeError: count takes a boolean, not a number"},
+		{"Boolean metric", "count() > 1", `TypeError: metric "count() > 1" is a boolean, not a number`},
+		{"Mixed comparison", `count(user < 1)`, "TypeError: cannot compare string < number"},
+		{"Unbalanced", "sum(files", "SyntaxError: unexpected end of expression"},
+		{"Trailing", "count() count()", `SyntaxError: unexpected "count" at 8`},
+		{"Unterminated string", `count(user == "a)`, "SyntaxError: unterminated string at 14"},
+		{"Unexpected character", "count() & 1", `SyntaxError: unexpected '&' at 8`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := types.ParseMetric(tc.source)
+			if err == nil {
+				t.Fatalf("expected an error for %q", tc.source)
+			}
+			if diff := cmp.Diff(tc.expected, err.Error()); diff != "" {
+				t.Errorf("ParseMetric() error mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	commits := []types.Commit{
+		{Timestamp: 100, User: "unknown", Repository: "payments-api", Files: 3},
+		{Timestamp: 200, User: "user1", Repository: "payments-web", Files: 600},
+		{Timestamp: 300, User: "user1", Repository: "payments-web", Files: 4},
+		{Timestamp: 400, User: "user2", Repository: "search", Files: 1},
+	}
+	testCases := []struct {
+		name     string
+		source   string
+		expected []int64
+	}{
+		{"Request example", `user != "unknown" and files < 500 and repository ~ "^payments-"`, []int64{300}},
+		{"Negated pattern", `repository !~ "web$"`, []int64{100, 400}},
+		{"Or and arithmetic", `timestamp / 100 == 1 or files * 2 > 1000`, []int64{100, 200}},
+		{"Not", `not (user == "user1")`, []int64{100, 400}},
+		{"String ordering", `user >= "user2"`, []int64{400}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := types.ParseFilter(tc.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var matched []int64
+			for _, c := range commits {
+				if e.Match(&c) {
+					matched = append(matched, c.Timestamp)
+				}
+			}
+			if diff := cmp.Diff(tc.expected, matched); diff != "" {
+				t.Errorf("Match() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"Aggregate", "count() > 1", "TypeError: aggregate count is not available in a filter"},
+		{"Not a boolean", "files + 1", `TypeError: filter "files + 1" is a number, not a boolean`},
+		{"Pattern not a string", "user ~ 1", "TypeError: ~ expects a string pattern at 7"},
+		{"Match on a number", `files ~ "1"`, "TypeError: ~ expects a string, not a number"},
+		{"Invalid pattern", `user ~ "("`, "SyntaxError: invalid pattern at 7: error parsing regexp: missing closing ): `(`"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := types.ParseFilter(tc.source)
+			if err == nil {
+				t.Fatalf("expected an error for %q", tc.source)
+			}
+			if diff := cmp.Diff(tc.expected, err.Error()); diff != "" {
+				t.Errorf("ParseFilter() error mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package main_test
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	for _, tc := range []struct {
+		precision uint8
+		distinct  int
+	}{
+		{10, 100},
+		{10, 100000},
+		{12, 50000},
+		{14, 1000000},
+	} {
+		t.Run(fmt.Sprintf("precision %d, %d distinct", tc.precision, tc.distinct), func(t *testing.T) {
+			h, err := types.NewHyperLogLog(tc.precision)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < tc.distinct; i++ {
+				h.Add("user" + strconv.Itoa(i))
+				h.Add("user" + strconv.Itoa(i/2)) // duplicates don't count
+			}
+			stderr := 1.04 / math.Sqrt(float64(int(1)<<tc.precision))
+			got := float64(h.Count())
+			if e := math.Abs(got-float64(tc.distinct)) / float64(tc.distinct); e > 3*stderr {
+				t.Errorf("Count() = %.0f, want %d within %.2f%%, error %.2f%%", got, tc.distinct, 300*stderr, 100*e)
+			}
+		})
+	}
+
+	for _, p := range []uint8{3, 17} {
+		if _, err := types.NewHyperLogLog(p); err == nil {
+			t.Errorf("NewHyperLogLog(%d) expected an error", p)
+		}
+	}
+}
+
+func TestAggregateApproxUsers(t *testing.T) {
+	exact := types.NewAggregate(0)
+	approx := types.NewAggregate(12)
+	for i := 0; i < 20000; i++ {
+		c := types.Commit{User: "user" + strconv.Itoa(i%5000)}
+		exact.Add(c)
+		approx.Add(c)
+		if i == 10 && approx.Sketch != nil {
+			t.Fatalf("Aggregate switched to a sketch with few users")
+		}
+	}
+	if approx.Sketch == nil || approx.Users != nil {
+		t.Fatalf("Aggregate didn't switch to a sketch")
+	}
+	if exact.DistinctUsers() != 5000 {
+		t.Errorf("exact DistinctUsers() = %d, want 5000", exact.DistinctUsers())
+	}
+	if got := approx.DistinctUsers(); math.Abs(float64(got)-5000) > 5000*3*0.0163 {
+		t.Errorf("approximate DistinctUsers() = %d, want about 5000", got)
+	}
+}
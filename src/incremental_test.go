@@ -0,0 +1,95 @@
+package main_test
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/FliCrz/blipper/src/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIngest(t *testing.T) {
+	commits := syntheticCommits(4000, 20)
+	sort.Slice(commits, func(i, j int) bool { return commits[i].Timestamp < commits[j].Timestamp })
+
+	for _, precision := range []uint8{0, 4} {
+		path := filepath.Join(t.TempDir(), "state.json")
+		state := utils.ReadState(path, false)
+		if n := utils.Ingest(&state, commits[:2500], "profile", 2, precision, false); n != 2500 {
+			t.Fatalf("Ingest() first run ingested %d commits, want 2500", n)
+		}
+		utils.WriteState(path, state, false)
+
+		// the second export overlaps the first one
+		state = utils.ReadState(path, false)
+		if n := utils.Ingest(&state, commits[1000:], "profile", 2, precision, false); n != 1500 {
+			t.Fatalf("Ingest() second run ingested %d commits, want 1500", n)
+		}
+
+		expected := utils.AggregateByRepository(commits, 1, precision, false)
+		first, last := utils.TimeRange(commits)
+		if state.First != first || state.Last != last {
+			t.Errorf("Ingest() window = %d, %d, want %d, %d", state.First, state.Last, first, last)
+		}
+		for name, a := range expected {
+			for _, f := range types.AggregateTargets {
+				if got, want := state.Repositories[name].ScoreByFilter(f), a.ScoreByFilter(f); got != want {
+					t.Errorf("precision %d, %s: ScoreByFilter(%s) = %d, want %d", precision, name, f, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestIngestOptions(t *testing.T) {
+	commits := syntheticCommits(200, 5)
+	sort.Slice(commits, func(i, j int) bool { return commits[i].Timestamp < commits[j].Timestamp })
+
+	testCases := []struct {
+		name    string
+		options string
+		wantErr bool
+	}{
+		{name: "Same options", options: "distinct 0"},
+		{name: "Other precision", options: "distinct 4", wantErr: true},
+		{name: "Other filters", options: "distinct 0 where", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := utils.ReadState(filepath.Join(t.TempDir(), "state.json"), false)
+			utils.Ingest(&state, commits[:100], "distinct 0", 1, 0, false)
+			defer func() {
+				if r := recover(); (r != nil) != tc.wantErr {
+					t.Errorf("Ingest() panic = %v, wantErr %v", r, tc.wantErr)
+				}
+			}()
+			utils.Ingest(&state, commits[100:], tc.options, 1, 0, false)
+		})
+	}
+}
+
+func TestAggregateMerge(t *testing.T) {
+	commits := syntheticCommits(3000, 1)
+	expected := types.NewAggregate(6)
+	for _, c := range commits {
+		expected.Add(c)
+	}
+	for _, split := range []int{10, 1500, 2990} {
+		a, b := types.NewAggregate(6), types.NewAggregate(6)
+		for _, c := range commits[:split] {
+			a.Add(c)
+		}
+		for _, c := range commits[split:] {
+			b.Add(c)
+		}
+		a.Merge(b)
+		if diff := cmp.Diff(expected.ScoreByFilter("distinctUsers"), a.ScoreByFilter("distinctUsers")); diff != "" {
+			t.Errorf("Merge() at %d distinct users mismatch (-want +got):\n%s", split, diff)
+		}
+		if a.Commits != expected.Commits || a.First != expected.First || a.Last != expected.Last {
+			t.Errorf("Merge() at %d = %+v, want %+v", split, a, expected)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInnerSource(t *testing.T) {
+	teams := map[string]string{
+		"user1": "team1",
+		"user2": "team1",
+		"user3": "team2",
+		"user4": "team3",
+	}
+	week := int64(7 * 24 * 60 * 60)
+
+	testCases := []struct {
+		name       string
+		repository types.Repository
+		expected   types.InnerSource
+	}{
+		{
+			name: "Owner only",
+			repository: types.Repository{Commits: []types.Commit{
+				{User: "user1"},
+				{User: "user2"},
+			}},
+			expected: types.InnerSource{OwnerTeam: "team1"},
+		},
+		{
+			name: "External contributors",
+			repository: types.Repository{Commits: []types.Commit{
+				{User: "user1", Timestamp: 0},
+				{User: "user1", Timestamp: week},
+				{User: "user2", Timestamp: week},
+				{User: "user3", Timestamp: 0},
+				{User: "user3", Timestamp: week},
+				{User: "user4", Timestamp: 0},
+				{User: "unknown", Timestamp: 0}, // ignored
+			}},
+			expected: types.InnerSource{
+				OwnerTeam:         "team1",
+				ExternalTeams:     2,
+				ExternalShare:     50,
+				ExternalRetention: 50,
+			},
+		},
+		{
+			name:       "No mapped users",
+			repository: types.Repository{Commits: []types.Commit{{User: "unknown"}}},
+			expected:   types.InnerSource{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.repository.InnerSource(teams, time.UTC)
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("InnerSource() mismatch (-want +got):\n%s", diff)
+			}
+			if score := tc.repository.ScoreByInnerSource("externalShare", teams, time.UTC); score != tc.expected.ExternalShare {
+				t.Errorf("ScoreByInnerSource(externalShare) = %d, want %d", score, tc.expected.ExternalShare)
+			}
+		})
+	}
+}
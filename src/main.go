@@ -1,7 +1,21 @@
 package main
 
 import (
+	"bufio"
+	"cmp"
 	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // IANA timezones without relying on the system database
+	"unicode"
 
 	"github.com/FliCrz/blipper/src/types"
 	"github.com/FliCrz/blipper/src/utils"
@@ -13,8 +27,21 @@ var (
 	scoringFilter string
 	version             = "0.0.1"
 	numberOfDays  int64 = 100
-	repos         []types.Repository
-	debug         = false
+	debug               = false
+	format              = "table"
+	teamsFile     string
+	teams         map[string]string
+	first, last   int64
+	trendWindow   int64 = 4
+	storePath           = "snapshots.jsonl"
+	concentrateBy       = "commits"
+	sizeMode            = "default"
+	sizeThreshold       = types.DefaultThresholds
+	location            = time.UTC
+	hoursWeight   float64
+	since, until  int64
+	workers       = runtime.NumCPU()
+	precision     uint8
 )
 
 var (
@@ -44,57 +71,1335 @@ func parseCommits(raw [][]string) (commits []types.Commit) {
 	return commits
 }
 
-func main() {
-	debugger("STARTING", debug)
-	filepath, numberOfDays, scoringFilter, debug = utils.ParseArgs(filepath, scoringFilter, version, numberOfDays, debug)
-	msg := fmt.Sprintf("You are requesting scoring for file: %s for %d days", filepath, numberOfDays)
+// trend computes the weekly trend of a repository over the dataset window.
+func trend(r *types.Repository) types.Trend {
+	return types.NewTrend(r.TimeSeries("week", first, last, location), int(trendWindow))
+}
 
-	raw := utils.ReadCsvToCommits(filepath, debug)
-	commits := parseCommits(raw)
+func scoreByTarget(r *types.Repository, f string) int64 {
+	if e, ok := expressions[f]; ok {
+		return r.ScoreByExpression(e)
+	}
+	switch f {
+	case "externalTeams", "externalShare", "externalRetention":
+		if teams == nil {
+			utils.ErrorLogger(fmt.Errorf("target %s requires --teams", f))
+		}
+		return r.ScoreByInnerSource(f, teams, location)
+	case "momentum":
+		return trend(r).Momentum()
+	case "flow":
+		return r.Sizes(sizeThreshold).Flow()
+	case "busFactor":
+		return r.Concentration(concentrateBy).BusFactor
+	case "gini":
+		return int64(math.Round(r.Concentration(concentrateBy).Gini * 100))
+	case "topShare":
+		return int64(math.Round(r.Concentration(concentrateBy).TopShare * 100))
+	default:
+		return r.ScoreByFilter(f)
+	}
+}
+
+// hoursModifier lowers the score by the share of weekend and off-hours commits, weighted by hoursWeight.
+func hoursModifier(r *types.Repository) int64 {
+	if hoursWeight == 0 {
+		return r.Score
+	}
+	p := types.NewActivityProfile(r.Commits, location)
+	return int64(math.Round(float64(r.Score) * (1 - hoursWeight*(p.WeekendShare+p.OffHoursShare))))
+}
+
+// weights of the default algorythm, applied to the aggregate of each repository
+var weights = []struct {
+	filter string
+	weight int64
+}{
+	{"files", 10},
+	{"additions", 1},
+	{"deletions", 1},
+	{"users", 5},
+	{"commits", 2},
+}
+
+// breakdown splits the default algorythm score of a repository by metric:
+// recency of the oldest commit plus weighted totals.
+func breakdown(a *types.Aggregate) []types.ScorePart {
+	oldest := types.Commit{Timestamp: a.First}
+	recency := 100 - oldest.ScoreByLastUpdate(last, first, numberOfDays)
+	parts := []types.ScorePart{{Metric: "recency", Weight: 1, Value: recency, Points: recency}}
+	for _, w := range weights {
+		v := a.ScoreByFilter(w.filter)
+		parts = append(parts, types.ScorePart{Metric: w.filter, Weight: w.weight, Value: v, Points: v * w.weight})
+	}
+	return parts
+}
+
+// defaultScore applies the default algorythm.
+func defaultScore(a *types.Aggregate) (score int64) {
+	for _, p := range breakdown(a) {
+		score += p.Points
+	}
+	return score
+}
+
+// descriptions of the metrics for reports
+var descriptions = map[string]string{
+	"recency":           "100 minus the chunk of the window holding the oldest commit",
+	"timestamp":         "sum of the commit timestamps",
+	"files":             "files changed",
+	"additions":         "lines added",
+	"deletions":         "lines deleted",
+	"users":             "commits, one per user contribution",
+	"commits":           "commits",
+	"distinctUsers":     "distinct users",
+	"externalTeams":     "external teams contributing",
+	"externalShare":     "percentage of commits by external teams",
+	"externalRetention": "percentage of external contributors active in more than one week",
+	"momentum":          "10 x slope + 100 x log2(ratio) + 10 x acceleration of weekly commits",
+	"busFactor":         "minimum authors accounting for half of the contributions",
+	"gini":              "Gini coefficient of the contributions per author, as a percentage, 100 for a single author",
+	"topShare":          "percentage of the contributions by the top author",
+	"flow":              "commits weighted by size: small 4, medium 3, large 2, huge 1",
+}
+
+// repoFilter keeps the repositories of --include-repo and drops those of --exclude-repo at ingest
+var repoFilter utils.RepositoryFilter
+
+// filters are the --where expressions commits must all match to be loaded
+var filters []*types.Expression
+
+// expressions are the named metrics declared with --metric and --metrics, selectable as targets
+var expressions = map[string]*types.Expression{}
+
+// defineMetric parses a name = expression definition, the name can't shadow a built-in target.
+func defineMetric(def string) {
+	name, source, ok := strings.Cut(def, "=")
+	name, source = strings.TrimSpace(name), strings.TrimSpace(source)
+	if !ok || name == "" || strings.ContainsFunc(name, func(c rune) bool { return !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' }) {
+		utils.ErrorLogger(fmt.Errorf("invalid metric definition, expected name = expression: %s", def))
+	}
+	if _, builtin := descriptions[name]; builtin && expressions[name] == nil {
+		utils.ErrorLogger(fmt.Errorf("metric %s shadows a built-in target", name))
+	}
+	e, err := types.ParseMetric(source)
+	utils.ErrorLogger(err)
+	expressions[name] = e
+	descriptions[name] = source
+}
+
+// algorithm describes the metrics and weights of the active scoring.
+func algorithm() []types.ScorePart {
+	if scoringFilter != "" {
+		return []types.ScorePart{{Metric: scoringFilter, Description: descriptions[scoringFilter], Weight: 1}}
+	}
+	parts := []types.ScorePart{{Metric: "recency", Description: descriptions["recency"], Weight: 1}}
+	for _, w := range weights {
+		parts = append(parts, types.ScorePart{Metric: w.filter, Description: descriptions[w.filter], Weight: w.weight})
+	}
+	return parts
+}
+
+// targetScore scores a repository before the hours modifier, from its aggregate when the target allows it.
+func targetScore(r *types.Repository, a *types.Aggregate) int64 {
+	switch {
+	case scoringFilter == "":
+		return defaultScore(a)
+	case slices.Contains(types.AggregateTargets, scoringFilter):
+		return a.ScoreByFilter(scoringFilter)
+	default:
+		return scoreByTarget(r, scoringFilter)
+	}
+}
+
+// rank scores every repository with the filter or the default algorythm and sorts them by score.
+func rank(commits []types.Commit) []types.Repository {
+	return rankAggregates(utils.AggregateByRepository(commits, workers, precision, debug), commits)
+}
+
+// rankIncremental ingests the commits newer than the state file, ranks from the updated aggregates
+// with recency as of the last ingested commit, and saves the state back.
+func rankIncremental(statePath string, commits []types.Commit) []types.Repository {
+	state := utils.ReadState(statePath, debug)
+	n := utils.Ingest(&state, commits, ingestProfile(), workers, precision, debug)
+	fmt.Printf("ingested %d new commits, as of %s\n", n, formatTime(state.Last, time.DateTime))
+	first, last = state.First, state.Last
+	repos := rankAggregates(state.Repositories, nil)
+	utils.WriteState(statePath, state, debug)
+	return repos
+}
+
+// ingestProfile identifies the options deciding which commits reach the incremental aggregates and how they count.
+func ingestProfile() string {
+	options := []string{fmt.Sprintf("distinct %d", precision)}
+	if since != 0 || until != 0 {
+		options = append(options, fmt.Sprintf("window %d %d", since, until))
+	}
+	for _, p := range repoFilter.Include {
+		options = append(options, "include "+p)
+	}
+	for _, p := range repoFilter.Exclude {
+		options = append(options, "exclude "+p)
+	}
+	for _, e := range filters {
+		options = append(options, "where "+e.Source)
+	}
+	return utils.ProfileHash(options...)
+}
+
+// rankAggregates scores repositories from their aggregates, commits are only grouped when
+// the target, the hours modifier or debugging need them and are nil in incremental mode.
+func rankAggregates(aggregates map[string]*types.Aggregate, commits []types.Commit) []types.Repository {
+	needsCommits := hoursWeight != 0 || (scoringFilter != "" && !slices.Contains(types.AggregateTargets, scoringFilter))
+	if needsCommits && commits == nil {
+		utils.ErrorLogger(fmt.Errorf("incremental mode supports the default algorythm and targets %v without --hours-weight", types.AggregateTargets))
+	}
+	var repoMap map[string]types.Repository
+	if needsCommits || (debug && commits != nil) {
+		repoMap = utils.GroupByRepositoryParallel(commits, workers, debug)
+	} else {
+		repoMap = make(map[string]types.Repository, len(aggregates))
+		for name := range aggregates {
+			repoMap[name] = types.Repository{Repository: name, Score: 1}
+		}
+	}
 
 	if scoringFilter != "" {
 		debugger(fmt.Sprintf("SCORING FILTER RECEIVED %s", scoringFilter), debug)
-		repoMap := utils.GroupByRepository(commits, debug)
-		msg = fmt.Sprintf("%s with filter: %s", msg, scoringFilter)
-		fmt.Println(msg)
+	} else {
+		debugger("APPLYING SCORING ALGORYTHM", debug)
+	}
+	repos := utils.ScoreRepositories(repoMap, workers, func(r *types.Repository) {
+		debugger(fmt.Sprintf("LOOPING REPOSITORY: %s", r.Repository), debug)
+		r.Score = targetScore(r, aggregates[r.Repository])
+		r.Score = hoursModifier(r)
+		if !debug {
+			r.Commits = nil
+		}
+	}, debug)
+
+	debugger("SORTING BY SCORE", debug)
+	utils.SortByScore(repos)
+	return repos
+}
+
+func timeseries(commits []types.Commit) {
+	repo := utils.GetArg("", "--repo")
+	interval := utils.GetArg("week", "-i", "--interval")
+	debugger(fmt.Sprintf("BUCKETING %s BY %s", repo, interval), debug)
+	r := types.Repository{Repository: repo, Commits: commits}
+	if repo != "" {
+		r = utils.GroupByRepositoryParallel(commits, workers, debug)[repo]
+		if len(r.Commits) == 0 {
+			utils.ErrorLogger(fmt.Errorf("repository not found: %s", repo))
+		}
+	}
+	buckets := r.TimeSeries(interval, first, last, location)
+	rows := [][]string{{"start", "commits", "churn", "users"}}
+	for _, b := range buckets {
+		rows = append(rows, []string{
+			formatTime(b.Start, time.DateOnly),
+			fmt.Sprint(b.Commits), fmt.Sprint(b.Churn), fmt.Sprint(b.Users),
+		})
+	}
+	utils.Print(format, buckets, rows)
+}
+
+func trends(commits []types.Commit) {
+	type entry struct {
+		Repository string `json:"repository"`
+		Momentum   int64  `json:"momentum"`
+		types.Trend
+	}
+	var repos []types.Repository
+	trendMap := make(map[string]types.Trend)
+	for _, r := range utils.GroupByRepositoryParallel(commits, workers, debug) {
+		debugger(fmt.Sprintf("LOOPING REPOSITORY: %s", r.Repository), debug)
+		trendMap[r.Repository] = trend(&r)
+		r.Score = trendMap[r.Repository].Momentum()
+		r.Commits = nil
+		repos = append(repos, r)
+	}
+	slices.SortFunc(repos, func(x, y types.Repository) int { return strings.Compare(x.Repository, y.Repository) })
+	utils.SortByScore(repos)
+	var entries []entry
+	rows := [][]string{{"repository", "momentum", "slope", "ratio", "acceleration"}}
+	for _, r := range repos {
+		t := trendMap[r.Repository]
+		entries = append(entries, entry{r.Repository, r.Score, t})
+		rows = append(rows, []string{
+			r.Repository, fmt.Sprint(r.Score),
+			fmt.Sprintf("%.2f", t.Slope), fmt.Sprintf("%.2f", t.Ratio), fmt.Sprintf("%.2f", t.Acceleration),
+		})
+	}
+	utils.Print(format, entries, rows)
+}
+
+// formatTime formats a timestamp in the requested timezone.
+func formatTime(ts int64, layout string) string {
+	return time.Unix(ts, 0).In(location).Format(layout)
+}
+
+// load reads and parses a commits CSV file, keeps the commits of the included repositories matching every --where filter
+// and within --since and --until, and sets the dataset window.
+func load(path string) []types.Commit {
+	raw := utils.ReadCsvToCommits(path, debug)
+	commits := parseCommits(raw)
+	if !repoFilter.Empty() {
+		debugger("FILTERING REPOSITORIES", debug)
+		kept := make(map[string]bool)
+		commits = slices.DeleteFunc(commits, func(c types.Commit) bool {
+			keep, ok := kept[c.Repository]
+			if !ok {
+				keep = repoFilter.Keep(c.Repository)
+				kept[c.Repository] = keep
+			}
+			return !keep
+		})
+	}
+	if len(filters) > 0 {
+		debugger(fmt.Sprintf("FILTERING COMMITS WHERE %d FILTERS MATCH", len(filters)), debug)
+		commits = slices.DeleteFunc(commits, func(c types.Commit) bool {
+			return slices.ContainsFunc(filters, func(e *types.Expression) bool { return !e.Match(&c) })
+		})
+	}
+	if since != 0 || until != 0 {
+		debugger(fmt.Sprintf("FILTERING COMMITS FROM %d TO %d", since, until), debug)
+		kept := commits[:0]
+		for _, c := range commits {
+			if c.Timestamp >= since && (until == 0 || c.Timestamp < until) {
+				kept = append(kept, c)
+			}
+		}
+		commits = kept
+	}
+	first, last = utils.TimeRange(commits)
+	if sizeMode == "quantiles" {
+		sizeThreshold = types.QuantileThresholds(commits)
+	}
+	return commits
+}
+
+func diff() {
+	var base, head []types.Repository
+	baseFile := utils.GetArg("", "--base")
+	headFile := utils.GetArg("", "--head")
+	split := utils.GetArg("", "--split")
+	switch {
+	case utils.GetArg("", "--base-snapshot") != "" && utils.GetArg("", "--head-snapshot") != "":
+		snapshots := utils.ReadSnapshots(storePath, debug)
+		base = utils.FindSnapshot(snapshots, utils.ParseInt(utils.GetArg("", "--base-snapshot"))).Repositories
+		head = utils.FindSnapshot(snapshots, utils.ParseInt(utils.GetArg("", "--head-snapshot"))).Repositories
+	case baseFile != "" && headFile != "":
+		base = rank(load(baseFile))
+		head = rank(load(headFile))
+	case split != "":
+		at := utils.ParseTime(split, location)
+		var before, after []types.Commit
+		for _, c := range load(filepath) {
+			if c.Timestamp < at {
+				before = append(before, c)
+			} else {
+				after = append(after, c)
+			}
+		}
+		first, last = utils.TimeRange(before)
+		base = rank(before)
+		first, last = utils.TimeRange(after)
+		head = rank(after)
+	default:
+		utils.ErrorLogger(fmt.Errorf("diff requires --base and --head, --base-snapshot and --head-snapshot or --split"))
+	}
+
+	changes := utils.DiffRankings(base, head)
+	rows := [][]string{{"repository", "status", "base rank", "head rank", "movement", "base score", "head score", "delta"}}
+	for _, c := range changes {
+		rows = append(rows, []string{
+			c.Repository, c.Status, fmt.Sprint(c.BaseRank), fmt.Sprint(c.HeadRank), fmt.Sprintf("%+d", c.Movement),
+			fmt.Sprint(c.BaseScore), fmt.Sprint(c.HeadScore), fmt.Sprintf("%+d", c.Delta),
+		})
+	}
+	utils.Print(format, changes, rows)
+}
+
+// profile identifies the options affecting scores, the concentration measure, size thresholds, approximate counting,
+// hours weight, dataset window, timezone, repository lists, filters and custom metrics only when used so the profiles
+// of earlier snapshots don't change.
+func profile() string {
+	options := []string{scoringFilter, fmt.Sprint(numberOfDays), fmt.Sprint(trendWindow), teamsFile}
+	if e, ok := expressions[scoringFilter]; ok {
+		options = append(options, e.Source)
+	}
+	if concentrateBy != "commits" {
+		options = append(options, "by "+concentrateBy)
+	}
+	if sizeMode != "default" {
+		options = append(options, "sizes "+sizeMode)
+	} else if sizeThreshold != types.DefaultThresholds {
+		options = append(options, fmt.Sprintf("size thresholds %v %v", sizeThreshold.Files, sizeThreshold.Lines))
+	}
+	if precision != 0 {
+		options = append(options, fmt.Sprintf("distinct approx %d", precision))
+	}
+	if hoursWeight != 0 {
+		options = append(options, fmt.Sprintf("hours weight %g", hoursWeight))
+	}
+	if since != 0 || until != 0 {
+		options = append(options, fmt.Sprintf("window %d %d", since, until))
+	}
+	if location != time.UTC {
+		options = append(options, "timezone "+location.String())
+	}
+	for _, p := range repoFilter.Include {
+		options = append(options, "include "+p)
+	}
+	for _, p := range repoFilter.Exclude {
+		options = append(options, "exclude "+p)
+	}
+	for _, e := range filters {
+		options = append(options, "where "+e.Source)
+	}
+	return utils.ProfileHash(options...)
+}
+
+func snapshots() {
+	list := utils.ReadSnapshots(storePath, debug)
+	rows := [][]string{{"id", "time", "profile", "filename", "repositories"}}
+	for n, s := range list {
+		rows = append(rows, []string{
+			fmt.Sprint(s.ID), formatTime(s.Timestamp, time.DateTime), s.Profile, s.Filename, fmt.Sprint(len(s.Repositories)),
+		})
+		list[n].Repositories = nil
+	}
+	utils.Print(format, list, rows)
+}
+
+func history() {
+	repo := utils.GetArg("", "--repo")
+	if repo == "" {
+		utils.ErrorLogger(fmt.Errorf("history requires --repo"))
+	}
+	type entry struct {
+		ID        int64  `json:"id"`
+		Timestamp int64  `json:"timestamp"`
+		Profile   string `json:"profile"`
+		Rank      int64  `json:"rank"`
+		Score     int64  `json:"score"`
+	}
+	var entries []entry
+	rows := [][]string{{"id", "time", "profile", "rank", "score"}}
+	for _, s := range utils.ReadSnapshots(storePath, debug) {
+		rank, score := s.Rank(repo)
+		entries = append(entries, entry{s.ID, s.Timestamp, s.Profile, rank, score})
+		rows = append(rows, []string{
+			fmt.Sprint(s.ID), formatTime(s.Timestamp, time.DateTime), s.Profile, fmt.Sprint(rank), fmt.Sprint(score),
+		})
+	}
+	utils.Print(format, entries, rows)
+}
+
+func alerts(commits []types.Commit) {
+	interval := utils.GetArg("week", "-i", "--interval")
+	baseline := utils.ParseInt(utils.GetArg("4", "--baseline"))
+	threshold := utils.ParseFloat(utils.GetArg("3", "--threshold"))
+	thresholds := map[string]float64{}
+	if path := utils.GetArg("", "--thresholds"); path != "" {
+		thresholds = utils.ReadThresholds(path, debug)
+	}
+	all := utils.HasArg("--all")
+	end := last + 1
+	if until != 0 {
+		end = until
+	}
+
+	found := []types.Alert{}
+	for _, r := range utils.GroupByRepositoryParallel(commits, workers, debug) {
+		debugger(fmt.Sprintf("LOOPING REPOSITORY: %s", r.Repository), debug)
+		t, ok := thresholds[r.Repository]
+		if !ok {
+			t = threshold
+		}
+		buckets := r.TimeSeries(interval, first, last, location)
+		// a partial last bucket would be compared with full baseline buckets and report false drops
+		if n := len(buckets); n > 0 && types.NextBucket(buckets[n-1].Start, interval, location) > end {
+			buckets = buckets[:n-1]
+		}
+		for _, a := range types.DetectAnomalies(buckets, int(baseline), t) {
+			if all || a.Start == buckets[len(buckets)-1].Start {
+				a.Repository = r.Repository
+				found = append(found, a)
+			}
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Start != found[j].Start {
+			return found[i].Start < found[j].Start
+		}
+		return found[i].Repository < found[j].Repository
+	})
+
+	rows := [][]string{{"repository", "start", "kind", "commits", "mean", "stddev", "zscore"}}
+	for _, a := range found {
+		rows = append(rows, []string{
+			a.Repository, formatTime(a.Start, time.DateOnly), a.Kind, fmt.Sprint(a.Commits),
+			fmt.Sprintf("%.2f", a.Mean), fmt.Sprintf("%.2f", a.StdDev), fmt.Sprintf("%.2f", a.ZScore),
+		})
+	}
+	utils.Print(format, found, rows)
+	if path := utils.GetArg("", "--out"); path != "" {
+		utils.WriteJSON(path, found, debug)
+	}
+	if url := utils.GetArg("", "--webhook"); url != "" && len(found) > 0 {
+		utils.PostJSON(url, found, debug)
+	}
+}
+
+func risk(commits []types.Commit) {
+	type entry struct {
+		Repository string `json:"repository"`
+		Commits    int64  `json:"commits"`
+		types.Concentration
+	}
+	var entries []entry
+	for _, r := range utils.GroupByRepositoryParallel(commits, workers, debug) {
+		debugger(fmt.Sprintf("LOOPING REPOSITORY: %s", r.Repository), debug)
+		entries = append(entries, entry{r.Repository, int64(len(r.Commits)), r.Concentration(concentrateBy)})
+	}
+	// most at risk first: lowest bus factor, then most active, repositories without known authors last
+	sort.Slice(entries, func(i, j int) bool {
+		if (entries[i].BusFactor == 0) != (entries[j].BusFactor == 0) {
+			return entries[j].BusFactor == 0
+		}
+		if entries[i].BusFactor != entries[j].BusFactor {
+			return entries[i].BusFactor < entries[j].BusFactor
+		}
+		if entries[i].Commits != entries[j].Commits {
+			return entries[i].Commits > entries[j].Commits
+		}
+		return entries[i].Repository < entries[j].Repository
+	})
+
+	rows := [][]string{{"repository", "commits", "authors", "bus factor", "gini", "top author", "top share"}}
+	for _, e := range entries {
+		rows = append(rows, []string{
+			e.Repository, fmt.Sprint(e.Commits), fmt.Sprint(e.Authors), fmt.Sprint(e.BusFactor),
+			fmt.Sprintf("%.2f", e.Gini), e.TopAuthor, fmt.Sprintf("%.0f%%", e.TopShare*100),
+		})
+	}
+	utils.Print(format, entries, rows)
+}
+
+func cohorts(commits []types.Commit) {
+	repo := utils.GetArg("", "--repo")
+	inactive := utils.ParseInt(utils.GetArg("28", "--inactive")) * 24 * 60 * 60
+	repoMap := utils.GroupByRepositoryParallel(commits, workers, debug)
+
+	if repo == "" {
+		type entry struct {
+			Repository   string `json:"repository"`
+			Contributors int64  `json:"contributors"`
+			Returning    int64  `json:"returning"` // active in more than one week
+			Inactive     int64  `json:"inactive"`
+			Retention    int64  `json:"retention"` // percentage of returning contributors
+		}
+		var entries []entry
 		for _, r := range repoMap {
 			debugger(fmt.Sprintf("LOOPING REPOSITORY: %s", r.Repository), debug)
-			r.Score = r.ScoreByFilter(scoringFilter)
-			if !debug {
-				r.Commits = nil
+			e := entry{Repository: r.Repository}
+			for _, c := range r.Contributors(last, inactive, location) {
+				e.Contributors++
+				if c.ActiveWeeks > 1 {
+					e.Returning++
+				}
+				if c.Inactive {
+					e.Inactive++
+				}
 			}
-			repos = append(repos, r)
+			if e.Contributors > 0 {
+				e.Retention = e.Returning * 100 / e.Contributors
+			}
+			entries = append(entries, e)
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].Contributors != entries[j].Contributors {
+				return entries[i].Contributors > entries[j].Contributors
+			}
+			return entries[i].Repository < entries[j].Repository
+		})
+		rows := [][]string{{"repository", "contributors", "returning", "inactive", "retention"}}
+		for _, e := range entries {
+			rows = append(rows, []string{
+				e.Repository, fmt.Sprint(e.Contributors), fmt.Sprint(e.Returning), fmt.Sprint(e.Inactive), fmt.Sprintf("%d%%", e.Retention),
+			})
+		}
+		utils.Print(format, entries, rows)
+		return
+	}
+
+	r, ok := repoMap[repo]
+	if !ok {
+		utils.ErrorLogger(fmt.Errorf("repository not found: %s", repo))
+	}
+	contributors := r.Contributors(last, inactive, location)
+	if utils.HasArg("--contributors") {
+		rows := [][]string{{"user", "first", "last", "commits", "active weeks", "inactive"}}
+		for _, c := range contributors {
+			rows = append(rows, []string{
+				c.User, formatTime(c.First, time.DateOnly), formatTime(c.Last, time.DateOnly),
+				fmt.Sprint(c.Commits), fmt.Sprint(c.ActiveWeeks), fmt.Sprint(c.Inactive),
+			})
+		}
+		utils.Print(format, contributors, rows)
+		return
+	}
+	list := r.Cohorts(last, location)
+	header := []string{"week", "size"}
+	if len(list) > 0 {
+		for n := range list[0].Retained {
+			header = append(header, fmt.Sprintf("+%d", n))
+		}
+	}
+	rows := [][]string{header}
+	for _, c := range list {
+		row := []string{formatTime(c.Week, time.DateOnly), fmt.Sprint(c.Size)}
+		for _, n := range c.Retained {
+			row = append(row, fmt.Sprint(n))
+		}
+		rows = append(rows, row)
+	}
+	utils.Print(format, struct {
+		Cohorts      []types.Cohort      `json:"cohorts"`
+		Contributors []types.Contributor `json:"contributors"`
+	}{list, contributors}, rows)
+}
+
+func stats(commits []types.Commit) {
+	all := types.Repository{Repository: "(all)", Commits: commits}
+	list := []types.Stats{all.Stats(location)}
+	var repoStats []types.Stats
+	for _, r := range utils.GroupByRepositoryParallel(commits, workers, debug) {
+		debugger(fmt.Sprintf("LOOPING REPOSITORY: %s", r.Repository), debug)
+		repoStats = append(repoStats, r.Stats(location))
+	}
+	sort.Slice(repoStats, func(i, j int) bool {
+		if repoStats[i].Commits != repoStats[j].Commits {
+			return repoStats[i].Commits > repoStats[j].Commits
+		}
+		return repoStats[i].Repository < repoStats[j].Repository
+	})
+	list = append(list, repoStats...)
+
+	rows := [][]string{{
+		"repository", "commits", "users", "first", "last", "files", "additions", "deletions",
+		"median files", "median additions", "median deletions", "active days", "commits/day", "unknown",
+	}}
+	for _, s := range list {
+		rows = append(rows, []string{
+			s.Repository, fmt.Sprint(s.Commits), fmt.Sprint(s.Users),
+			formatTime(s.First, time.DateTime), formatTime(s.Last, time.DateTime),
+			fmt.Sprint(s.Files), fmt.Sprint(s.Additions), fmt.Sprint(s.Deletions),
+			fmt.Sprint(s.MedianFiles), fmt.Sprint(s.MedianAdditions), fmt.Sprint(s.MedianDeletions),
+			fmt.Sprint(s.ActiveDays), fmt.Sprintf("%.2f", s.CommitsPerActiveDay), fmt.Sprintf("%.0f%%", s.UnknownShare*100),
+		})
+	}
+	utils.Print(format, list, rows)
+}
+
+func sizes(commits []types.Commit) {
+	type entry struct {
+		Repository string `json:"repository"`
+		types.Sizes
+		Flow int64 `json:"flow"`
+	}
+	var entries []entry
+	for _, r := range utils.GroupByRepositoryParallel(commits, workers, debug) {
+		debugger(fmt.Sprintf("LOOPING REPOSITORY: %s", r.Repository), debug)
+		s := r.Sizes(sizeThreshold)
+		entries = append(entries, entry{r.Repository, s, s.Flow()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Flow != entries[j].Flow {
+			return entries[i].Flow > entries[j].Flow
+		}
+		return entries[i].Repository < entries[j].Repository
+	})
+
+	debugger(fmt.Sprintf("SIZE THRESHOLDS %+v", sizeThreshold), debug)
+	rows := [][]string{{"repository", "small", "medium", "large", "huge", "flow"}}
+	for _, e := range entries {
+		rows = append(rows, []string{
+			e.Repository, fmt.Sprint(e.Small), fmt.Sprint(e.Medium), fmt.Sprint(e.Large), fmt.Sprint(e.Huge), fmt.Sprint(e.Flow),
+		})
+	}
+	utils.Print(format, struct {
+		Thresholds   types.SizeThresholds `json:"thresholds"`
+		Repositories []entry              `json:"repositories"`
+	}{sizeThreshold, entries}, rows)
+}
+
+func activity(commits []types.Commit) {
+	repo := utils.GetArg("", "--repo")
+	user := utils.GetArg("", "--user")
+	var selected []types.Commit
+	for _, c := range commits {
+		if (repo == "" || c.Repository == repo) && (user == "" || c.User == user) {
+			selected = append(selected, c)
+		}
+	}
+	if len(selected) == 0 {
+		utils.ErrorLogger(fmt.Errorf("no commits found for repository %q and user %q", repo, user))
+	}
+	p := types.NewActivityProfile(selected, location)
+	if format == "table" {
+		fmt.Print(p)
+		return
+	}
+	rows := [][]string{{"weekday"}}
+	for h := 0; h < 24; h++ {
+		rows[0] = append(rows[0], fmt.Sprint(h))
+	}
+	for d, hours := range p.Heatmap {
+		row := []string{time.Weekday(d).String()}
+		for _, n := range hours {
+			row = append(row, fmt.Sprint(n))
 		}
+		rows = append(rows, row)
+	}
+	utils.Print(format, p, rows)
+}
+
+// runRank ranks the input file, prints the top list and saves a snapshot if requested.
+func runRank() []types.Repository {
+	var repos []types.Repository
+	if statePath := utils.GetArg("", "--incremental"); statePath != "" {
+		repos = rankIncremental(statePath, load(filepath))
 	} else {
-		debugger("APPLYING SCORING ALGORYTHM", debug)
-		fmt.Println(msg)
-		sorted := utils.SortCommitsDecreasing(commits, "timestamp", debug)
-		repoMap := utils.GroupByRepository(sorted, debug)
-		for _, r := range repoMap {
-			debugger(fmt.Sprintf("LOOPING REPOSITORY: %s", r.Repository), debug)
-			for _, c := range r.Commits {
-				r.Score = 100 - c.ScoreByLastUpdate(sorted[0].Timestamp, sorted[len(sorted)-1].Timestamp, numberOfDays)
+		repos = rank(load(filepath))
+	}
+	fmt.Printf("\n%v\n", repos[:min(9, len(repos))])
+	if utils.HasArg("--save") {
+		s := utils.AppendSnapshot(storePath, types.Snapshot{
+			Timestamp:    time.Now().Unix(),
+			Profile:      profile(),
+			Filename:     filepath,
+			Repositories: repos,
+		}, debug)
+		fmt.Printf("saved snapshot %d to %s\n", s.ID, storePath)
+	}
+	return repos
+}
+
+// watch reruns the ranking whenever the input file changes, printing the rank movements of the top repositories.
+func watch(repos []types.Repository) {
+	poll := time.Duration(utils.ParseFloat(utils.GetArg("2", "--poll")) * float64(time.Second))
+	if poll <= 0 {
+		utils.ErrorLogger(fmt.Errorf("--poll must be a positive number of seconds: %s", utils.GetArg("2", "--poll")))
+	}
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	fmt.Printf("\nwatching %s, press ctrl+c to stop\n", filepath)
+	utils.WatchFile(filepath, poll, stop, func() {
+		fmt.Printf("\n%s: %s changed\n", formatTime(time.Now().Unix(), time.DateTime), filepath)
+		head := runRank()
+		top := make(map[string]bool)
+		for _, r := range slices.Concat(repos[:min(9, len(repos))], head[:min(9, len(head))]) {
+			top[r.Repository] = true
+		}
+		rows := [][]string{{"repository", "status", "rank", "movement", "score", "delta"}}
+		for _, c := range utils.DiffRankings(repos, head) {
+			if top[c.Repository] && (c.Status != "same" || c.Delta != 0) {
+				rows = append(rows, []string{
+					c.Repository, c.Status, fmt.Sprint(c.HeadRank), fmt.Sprintf("%+d", c.Movement), fmt.Sprint(c.HeadScore), fmt.Sprintf("%+d", c.Delta),
+				})
 			}
-			for n, s := range []string{"files", "additions", "deletions", "users", "commits"} {
-				debugger(fmt.Sprintf("LOOPING FILTER: %s", s), debug)
-				if n == 0 {
-					r.Score += r.ScoreByFilter(s) * 10
-				} else if n == 3 {
-					r.Score += r.ScoreByFilter(s) * 5
-				} else if n == 4 {
-					r.Score += r.ScoreByFilter(s) * 2
-				} else {
-					r.Score += r.ScoreByFilter(s) * 1
+		}
+		fmt.Println()
+		utils.Print("table", nil, rows)
+		repos = head
+	}, debug)
+}
+
+// collectMetrics loads and ranks the input file, timing each stage, into Prometheus metrics.
+func collectMetrics() []utils.Metric {
+	start := time.Now()
+	commits := load(filepath)
+	loaded := time.Now()
+	aggregates := utils.AggregateByRepository(commits, workers, precision, debug)
+	aggregated := time.Now()
+	repos := rankAggregates(aggregates, commits)
+	ranked := time.Now()
+
+	score := utils.Metric{Name: "blipper_repository_score", Help: "Activity score of the repository.", Type: "gauge"}
+	commitsTotal := utils.Metric{Name: "blipper_repository_commits_total", Help: "Commits of the repository in the dataset.", Type: "gauge"}
+	users := utils.Metric{Name: "blipper_repository_distinct_users", Help: "Distinct users committing to the repository.", Type: "gauge"}
+	for _, r := range repos {
+		labels := map[string]string{"repository": r.Repository}
+		a := aggregates[r.Repository]
+		score.Samples = append(score.Samples, utils.Sample{Labels: labels, Value: float64(r.Score)})
+		commitsTotal.Samples = append(commitsTotal.Samples, utils.Sample{Labels: labels, Value: float64(a.Commits)})
+		users.Samples = append(users.Samples, utils.Sample{Labels: labels, Value: float64(a.DistinctUsers())})
+	}
+	timings := utils.Metric{Name: "blipper_pipeline_duration_seconds", Help: "Duration of the last run of each pipeline stage.", Type: "gauge"}
+	for _, t := range []struct {
+		stage    string
+		duration time.Duration
+	}{
+		{"load", loaded.Sub(start)},
+		{"aggregate", aggregated.Sub(loaded)},
+		{"rank", ranked.Sub(aggregated)},
+	} {
+		timings.Samples = append(timings.Samples, utils.Sample{Labels: map[string]string{"stage": t.stage}, Value: t.duration.Seconds()})
+	}
+	return []utils.Metric{
+		score, commitsTotal, users, timings,
+		{Name: "blipper_dataset_last_commit_timestamp_seconds", Help: "Timestamp of the newest commit in the dataset.", Type: "gauge",
+			Samples: []utils.Sample{{Value: float64(last)}}},
+	}
+}
+
+// metrics writes a node_exporter textfile with --textfile, otherwise serves /metrics on --addr,
+// collecting again whenever the input file changed since the previous scrape.
+func metrics() {
+	if path := utils.GetArg("", "--textfile"); path != "" {
+		utils.WriteTextfile(path, collectMetrics(), debug)
+		return
+	}
+
+	addr := utils.GetArg(":9090", "--addr")
+	var mu sync.Mutex
+	var cached []utils.Metric
+	var modTime time.Time
+	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		info, err := os.Stat(filepath)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if cached == nil || !info.ModTime().Equal(modTime) {
+			debugger(fmt.Sprintf("COLLECTING METRICS FOR %s", filepath), debug)
+			cached, modTime = collectMetrics(), info.ModTime()
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := utils.WriteMetrics(w, cached); err != nil {
+			debugger(fmt.Sprintf("WRITING METRICS FAILED: %v", err), debug)
+		}
+	})
+	fmt.Printf("serving metrics of %s on %s/metrics\n", filepath, addr)
+	utils.ErrorLogger(http.ListenAndServe(addr, nil))
+}
+
+// buildReport ranks the commits and details the top repositories.
+func buildReport(commits []types.Commit, top int) types.Report {
+	repos := rank(commits)
+	aggregates := utils.AggregateByRepository(commits, workers, precision, debug)
+	repoMap := utils.GroupByRepositoryParallel(commits, workers, debug)
+	all := types.Repository{Repository: "(all)", Commits: commits}
+	report := types.Report{
+		Generated:    time.Now().Unix(),
+		Filename:     filepath,
+		Target:       scoringFilter,
+		Algorithm:    algorithm(),
+		NumberOfDays: numberOfDays,
+		Timezone:     location.String(),
+		Summary:      all.Stats(location),
+	}
+	for n, r := range repos[:min(top, len(repos))] {
+		full := repoMap[r.Repository]
+		a := aggregates[r.Repository]
+		var parts []types.ScorePart
+		if scoringFilter == "" {
+			parts = breakdown(a)
+		} else {
+			v := targetScore(&full, a)
+			parts = []types.ScorePart{{Metric: scoringFilter, Weight: 1, Value: v, Points: v}}
+		}
+		var sum int64
+		for _, p := range parts {
+			sum += p.Points
+		}
+		if sum != r.Score {
+			parts = append(parts, types.ScorePart{Metric: "hours modifier", Weight: 1, Value: r.Score - sum, Points: r.Score - sum})
+		}
+		report.Repositories = append(report.Repositories, types.RankedRepository{
+			Rank:            int64(n + 1),
+			Repository:      r.Repository,
+			Score:           r.Score,
+			Breakdown:       parts,
+			Weekly:          full.TimeSeries("week", first, last, location),
+			TopContributors: full.TopContributors(5),
+		})
+	}
+	return report
+}
+
+func report(commits []types.Commit) {
+	r := buildReport(commits, int(utils.ParseInt(utils.GetArg("10", "--top"))))
+	if dir := utils.GetArg("", "--html"); dir != "" {
+		path := utils.WriteHTMLReport(dir, r, debug)
+		fmt.Printf("report written to %s\n", path)
+	}
+	if utils.HasArg("--markdown") {
+		tmpl := utils.MarkdownTemplate(utils.GetArg("", "--template"), debug)
+		out := os.Stdout
+		if path := utils.GetArg("", "--out"); path != "" {
+			f, err := os.Create(path)
+			utils.ErrorLogger(err)
+			defer f.Close()
+			out = f
+		}
+		utils.ErrorLogger(tmpl.Execute(out, r))
+	}
+	if !utils.HasArg("--markdown") && utils.GetArg("", "--html") == "" {
+		utils.ErrorLogger(fmt.Errorf("report requires --html and/or --markdown"))
+	}
+}
+
+func chart(commits []types.Commit) string {
+	top := int(utils.ParseInt(utils.GetArg("10", "--top")))
+	repo := utils.GetArg("", "--repo")
+	switch kind := utils.GetArg("breakdown", "--type"); kind {
+	case "breakdown":
+		r := buildReport(commits, top)
+		var labels, names []string
+		index := map[string]int{}
+		for _, rr := range r.Repositories {
+			for _, p := range rr.Breakdown {
+				if _, ok := index[p.Metric]; !ok {
+					index[p.Metric] = len(names)
+					names = append(names, p.Metric)
 				}
 			}
-			if !debug {
-				r.Commits = nil
+		}
+		parts := make([][]int64, len(r.Repositories))
+		for n, rr := range r.Repositories {
+			labels = append(labels, rr.Repository)
+			parts[n] = make([]int64, len(names))
+			for _, p := range rr.Breakdown {
+				parts[n][index[p.Metric]] = p.Points
+			}
+		}
+		return utils.StackedBarChart("Score breakdown", labels, names, parts)
+	case "activity":
+		repos := rank(commits)
+		if repo != "" {
+			repos = slices.DeleteFunc(repos, func(r types.Repository) bool { return r.Repository != repo })
+		}
+		repos = repos[:min(top, len(repos))]
+		repoMap := utils.GroupByRepositoryParallel(commits, workers, debug)
+		var labels []string
+		var series []utils.Series
+		for _, r := range repos {
+			full := repoMap[r.Repository]
+			buckets := full.TimeSeries("week", first, last, location)
+			s := utils.Series{Name: r.Repository}
+			labels = labels[:0]
+			for _, b := range buckets {
+				labels = append(labels, formatTime(b.Start, "2006-01-02"))
+				s.Values = append(s.Values, b.Commits)
+			}
+			series = append(series, s)
+		}
+		return utils.LineChart("Weekly commits", labels, series)
+	case "concentration":
+		repoMap := utils.GroupByRepositoryParallel(commits, workers, debug)
+		r, ok := repoMap[repo]
+		if !ok {
+			utils.ErrorLogger(fmt.Errorf("concentration chart requires --repo with a known repository: %q", repo))
+		}
+		// unknown authors can't be told apart, their commits go to the others slice
+		var parts []utils.Slice
+		var shown int64
+		for _, c := range r.TopContributors(len(utils.Colors)) {
+			if c.User != "unknown" && len(parts) < len(utils.Colors)-1 {
+				parts = append(parts, utils.Slice{Label: c.User, Value: c.Commits})
+				shown += c.Commits
 			}
-			repos = append(repos, r)
 		}
+		if others := int64(len(r.Commits)) - shown; others > 0 {
+			label := "others"
+			if slices.ContainsFunc(r.Commits, func(c types.Commit) bool { return c.User == "unknown" }) {
+				label = "others incl. unknown"
+			}
+			parts = append(parts, utils.Slice{Label: label, Value: others})
+		}
+		c := r.Concentration("commits")
+		return utils.PieChart(fmt.Sprintf("%s commits by author (bus factor %d, gini %.2f)", repo, c.BusFactor, c.Gini), parts)
+	default:
+		utils.ErrorLogger(fmt.Errorf("TypeError: unsupported chart type: %s", kind))
 	}
+	return ""
+}
 
-	debugger("SORTING BY SCORE", debug)
-	utils.SortByScore(repos)
-	fmt.Printf("\n%v\n", repos[0:9])
+// windows of the terminal UI in days ending at the last commit, 0 keeps the whole dataset
+var windows = []int64{0, 365, 180, 90, 30, 14, 7}
+
+// sortMetrics of the terminal UI, read from the aggregate of each repository besides the score
+var sortMetrics = []string{"score", "commits", "files", "additions", "deletions", "distinctUsers"}
+
+// browser is the state of the terminal UI.
+type browser struct {
+	all        []types.Commit // the loaded dataset, windows are cut from it
+	commits    []types.Commit
+	window     int
+	repos      []types.Repository
+	aggregates map[string]*types.Aggregate
+	sortBy     int
+	weight     int
+	cursor     int
+	detail     *types.Repository // drilled into when set
+	scroll     int
+}
+
+// refresh cuts the window, then aggregates, scores and sorts the repositories again.
+func (b *browser) refresh() {
+	b.commits = b.all
+	if days := windows[b.window]; days != 0 {
+		_, end := utils.TimeRange(b.all)
+		b.commits = slices.DeleteFunc(slices.Clone(b.all), func(c types.Commit) bool {
+			return c.Timestamp < end-days*24*60*60
+		})
+	}
+	first, last = utils.TimeRange(b.commits)
+	b.aggregates = utils.AggregateByRepository(b.commits, workers, precision, debug)
+	b.repos = rankAggregates(b.aggregates, b.commits)
+	if metric := sortMetrics[b.sortBy]; metric != "score" {
+		slices.SortStableFunc(b.repos, func(x, y types.Repository) int {
+			return cmp.Compare(b.aggregates[y.Repository].ScoreByFilter(metric), b.aggregates[x.Repository].ScoreByFilter(metric))
+		})
+	}
+	b.cursor = min(b.cursor, max(len(b.repos)-1, 0))
+}
+
+// reload refreshes the ranking and keeps the cursor, or the view drilled into, on the same repository
+// as long as it has commits in the window.
+func (b *browser) reload() {
+	name := ""
+	if len(b.repos) > 0 {
+		name = b.repos[b.cursor].Repository
+	}
+	b.refresh()
+	if n := slices.IndexFunc(b.repos, func(r types.Repository) bool { return r.Repository == name }); n >= 0 {
+		b.cursor = n
+	} else {
+		b.detail = nil
+	}
+	if b.detail != nil {
+		b.open()
+	}
+}
+
+// open drills into the repository under the cursor.
+func (b *browser) open() {
+	if len(b.repos) == 0 {
+		return
+	}
+	r := types.Repository{Repository: b.repos[b.cursor].Repository, Score: b.repos[b.cursor].Score}
+	for _, c := range b.commits {
+		if c.Repository == r.Repository {
+			r.Commits = append(r.Commits, c)
+		}
+	}
+	slices.SortStableFunc(r.Commits, func(x, y types.Commit) int { return cmp.Compare(y.Timestamp, x.Timestamp) })
+	b.detail, b.scroll = &r, 0
+}
+
+// handle applies a key press and reports whether to quit.
+func (b *browser) handle(key string, rows int) bool {
+	page := max(rows-8, 1)
+	move := func(n int) {
+		if b.detail != nil {
+			b.scroll = max(min(b.scroll+n, len(b.detail.Commits)-1), 0)
+		} else {
+			b.cursor = max(min(b.cursor+n, len(b.repos)-1), 0)
+		}
+	}
+	switch key {
+	case "q", "ctrl+c":
+		return true
+	case "up", "k":
+		move(-1)
+	case "down", "j":
+		move(1)
+	case "pgup":
+		move(-page)
+	case "pgdown", " ":
+		move(page)
+	case "home", "g":
+		move(-len(b.all))
+	case "end", "G":
+		move(len(b.all))
+	case "enter", "right", "l":
+		if b.detail == nil {
+			b.open()
+		}
+	case "esc", "backspace", "left", "h":
+		b.detail = nil
+	case "s":
+		b.sortBy = (b.sortBy + 1) % len(sortMetrics)
+		b.reload()
+	case "tab":
+		b.weight = (b.weight + 1) % len(weights)
+	case "+", "=", "-":
+		step := max(weights[b.weight].weight/10, 1)
+		if key == "-" {
+			step = -min(step, weights[b.weight].weight)
+		}
+		weights[b.weight].weight += step
+		b.reload()
+	case "[":
+		b.window = min(b.window+1, len(windows)-1)
+		b.reload()
+	case "]":
+		b.window = max(b.window-1, 0)
+		b.reload()
+	}
+	return false
+}
+
+// render draws the ranked list, or the repository drilled into, to fit the terminal.
+func (b *browser) render(rows, cols int) string {
+	var out strings.Builder
+	// styled truncates the text to the terminal width before wrapping it in the attribute
+	styled := func(attr, format string, a ...any) {
+		s := fmt.Sprintf(format, a...)
+		if r := []rune(s); len(r) > cols {
+			s = string(r[:cols])
+		}
+		if attr != "" {
+			s = attr + s + utils.Reset
+		}
+		out.WriteString(s + "\n")
+	}
+	line := func(format string, a ...any) { styled("", format, a...) }
+	window := "all"
+	if days := windows[b.window]; days != 0 {
+		window = fmt.Sprintf("last %d days", days)
+	}
+	out.WriteString(utils.ClearScreen)
+	styled(utils.Bold, "blipper  %s  window: %s (%s to %s)  sort: %s", filepath, window,
+		formatTime(first, time.DateOnly), formatTime(last, time.DateOnly), sortMetrics[b.sortBy])
+	var ws []string
+	for n, w := range weights {
+		if n == b.weight {
+			ws = append(ws, fmt.Sprintf("[%s %d]", w.filter, w.weight))
+		} else {
+			ws = append(ws, fmt.Sprintf(" %s %d ", w.filter, w.weight))
+		}
+	}
+	if scoringFilter != "" {
+		line("target: %s (weights only apply to the default algorythm)", scoringFilter)
+	} else {
+		line("weights: %s", strings.Join(ws, " "))
+	}
+	line("")
+
+	if b.detail == nil {
+		b.renderList(styled, rows-6)
+		line("")
+		line("j/k move  enter open  s sort  tab weight  +/- adjust  [/] window  q quit")
+	} else {
+		b.renderDetail(line, rows-6)
+		line("")
+		line("j/k scroll commits  esc back  s sort  tab weight  +/- adjust  [/] window  q quit")
+	}
+	return out.String()
+}
+
+func (b *browser) renderList(styled func(string, string, ...any), height int) {
+	styled("", "%5s  %-24s %12s %8s %8s %10s %10s %6s", "#", "repository", "score", "commits", "files", "additions", "deletions", "users")
+	visible := max(height-1, 1) // below the header, the cursor row is always drawn
+	start := max(min(b.cursor-visible/2, len(b.repos)-visible), 0)
+	for n := start; n < min(start+visible, len(b.repos)); n++ {
+		r, a := b.repos[n], b.aggregates[b.repos[n].Repository]
+		attr := ""
+		if n == b.cursor {
+			attr = utils.Reverse
+		}
+		styled(attr, "%5d  %-24s %12d %8d %8d %10d %10d %6d", n+1, r.Repository, r.Score, a.Commits, a.Files, a.Additions, a.Deletions, a.DistinctUsers())
+	}
+}
+
+func (b *browser) renderDetail(line func(string, ...any), height int) {
+	r, a := b.detail, b.aggregates[b.detail.Repository]
+	var weekly []int64
+	for _, bucket := range r.TimeSeries("week", first, last, location) {
+		weekly = append(weekly, bucket.Commits)
+	}
+	line("%s  score %d  rank %d of %d", r.Repository, r.Score, b.cursor+1, len(b.repos))
+	if scoringFilter == "" {
+		var parts []string
+		for _, p := range breakdown(a) {
+			parts = append(parts, fmt.Sprintf("%s %d", p.Metric, p.Points))
+		}
+		line("breakdown: %s", strings.Join(parts, ", "))
+	}
+	line("weekly commits: %s", utils.TextSparkline(weekly))
+	var contributors []string
+	for _, c := range r.TopContributors(5) {
+		contributors = append(contributors, fmt.Sprintf("%s (%d)", c.User, c.Commits))
+	}
+	line("top contributors of %d: %s", a.DistinctUsers(), strings.Join(contributors, ", "))
+	line("")
+	line("%-19s  %-16s %6s %10s %10s", "date", "user", "files", "additions", "deletions")
+	for _, c := range r.Commits[b.scroll:max(min(b.scroll+height-7, len(r.Commits)), b.scroll)] {
+		line("%-19s  %-16s %6d %10d %10d", formatTime(c.Timestamp, time.DateTime), c.User, c.Files, c.Additions, c.Deletions)
+	}
+}
+
+// tui browses the ranking interactively until q is pressed.
+func tui(commits []types.Commit) {
+	restore, err := utils.RawTerminal(debug)
+	utils.ErrorLogger(err)
+	defer restore()
+	fmt.Print(utils.AltScreen)
+	defer fmt.Print(utils.MainScreen)
+
+	b := &browser{all: commits}
+	b.refresh()
+	keys := bufio.NewReader(os.Stdin)
+	for {
+		rows, cols := utils.TerminalSize()
+		fmt.Print(b.render(rows, cols))
+		key, err := utils.ReadKey(keys)
+		if err != nil || b.handle(key, rows) {
+			return
+		}
+	}
+}
+
+func main() {
+	debugger("STARTING", debug)
+	filepath, numberOfDays, scoringFilter, debug = utils.ParseArgs(filepath, scoringFilter, version, numberOfDays, debug)
+	format = utils.GetArg(format, "--format")
+	workers = int(utils.ParseInt(utils.GetArg(fmt.Sprint(workers), "--workers")))
+	switch distinct := utils.GetArg("exact", "--distinct"); distinct {
+	case "exact":
+	case "approx":
+		precision = uint8(utils.ParseInt(utils.GetArg("12", "--precision")))
+		_, err := types.NewHyperLogLog(precision)
+		utils.ErrorLogger(err)
+	default:
+		utils.ErrorLogger(fmt.Errorf("TypeError: unsupported distinct counting: %s", distinct))
+	}
+	if tz := utils.GetArg("", "--timezone"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		utils.ErrorLogger(err)
+		location = loc
+	}
+	if v := utils.GetArg("", "--since"); v != "" {
+		since = utils.ParseTime(v, location)
+	}
+	if v := utils.GetArg("", "--until"); v != "" {
+		until = utils.ParseTime(v, location)
+	}
+	trendWindow = utils.ParseInt(utils.GetArg(fmt.Sprint(trendWindow), "--window"))
+	storePath = utils.GetArg(storePath, "--store")
+	concentrateBy = utils.GetArg(concentrateBy, "--by")
+	sizeMode = utils.GetArg(sizeMode, "--sizes")
+	switch sizeMode {
+	case "default":
+	case "quantiles":
+		if utils.HasArg("--size-files", "--size-lines") {
+			utils.ErrorLogger(fmt.Errorf("--sizes quantiles computes the size thresholds, it can't be combined with --size-files or --size-lines"))
+		}
+	default:
+		utils.ErrorLogger(fmt.Errorf("TypeError: unsupported sizes: %s", sizeMode))
+	}
+	hoursWeight = utils.ParseFloat(utils.GetArg("0", "--hours-weight"))
+	if hoursWeight < 0 || hoursWeight > 1 {
+		utils.ErrorLogger(fmt.Errorf("--hours-weight must be between 0 and 1: %g", hoursWeight))
+	}
+
+	for flag, bounds := range map[string]*[3]int64{"--size-files": &sizeThreshold.Files, "--size-lines": &sizeThreshold.Lines} {
+		if v := utils.GetArg("", flag); v != "" {
+			b, err := types.ParseBounds(v)
+			utils.ErrorLogger(err)
+			*bounds = b
+		}
+	}
+	include, exclude := utils.GetArgs("--include-repo"), utils.GetArgs("--exclude-repo")
+	if path := utils.GetArg("", "--include-file"); path != "" {
+		include = append(include, utils.ReadLines(path, debug)...)
+	}
+	if path := utils.GetArg("", "--exclude-file"); path != "" {
+		exclude = append(exclude, utils.ReadLines(path, debug)...)
+	}
+	var err error
+	repoFilter, err = utils.NewRepositoryFilter(include, exclude)
+	utils.ErrorLogger(err)
+	for _, where := range utils.GetArgs("--where") {
+		e, err := types.ParseFilter(where)
+		utils.ErrorLogger(err)
+		filters = append(filters, e)
+	}
+	defs := utils.GetArgs("--metric")
+	if path := utils.GetArg("", "--metrics"); path != "" {
+		defs = append(utils.ReadLines(path, debug), defs...)
+	}
+	for _, def := range defs {
+		defineMetric(def)
+	}
+	teamsFile = utils.GetArg(teamsFile, "--teams")
+	if teamsFile != "" {
+		teams = utils.ReadTeams(teamsFile, debug)
+	}
+
+	switch command := utils.GetCommand(); command {
+	case "rank":
+		msg := fmt.Sprintf("You are requesting scoring for file: %s for %d days", filepath, numberOfDays)
+		if scoringFilter != "" {
+			msg = fmt.Sprintf("%s with filter: %s", msg, scoringFilter)
+		}
+		fmt.Println(msg)
+		repos := runRank()
+		if utils.HasArg("--watch") {
+			watch(repos)
+		}
+	case "timeseries":
+		timeseries(load(filepath))
+	case "trend":
+		trends(load(filepath))
+	case "diff":
+		diff()
+	case "snapshots":
+		snapshots()
+	case "history":
+		history()
+	case "alerts":
+		alerts(load(filepath))
+	case "risk":
+		risk(load(filepath))
+	case "cohorts":
+		cohorts(load(filepath))
+	case "stats":
+		stats(load(filepath))
+	case "sizes":
+		sizes(load(filepath))
+	case "activity":
+		activity(load(filepath))
+	case "metrics":
+		metrics()
+	case "report":
+		report(load(filepath))
+	case "tui":
+		tui(load(filepath))
+	case "chart":
+		svg := chart(load(filepath))
+		if path := utils.GetArg("", "-o", "--out"); path != "" {
+			utils.ErrorLogger(os.WriteFile(path, []byte(svg), 0o644))
+			fmt.Printf("chart written to %s\n", path)
+		} else {
+			fmt.Print(svg)
+		}
+	default:
+		utils.ErrorLogger(fmt.Errorf("unknown command: %s", command))
+	}
 }
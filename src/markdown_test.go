@@ -0,0 +1,54 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/FliCrz/blipper/src/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarkdownTemplate(t *testing.T) {
+	report := types.Report{
+		Filename:     "commits.csv",
+		Timezone:     "UTC",
+		NumberOfDays: 100,
+		Algorithm:    []types.ScorePart{{Metric: "files", Description: "files changed", Weight: 10}},
+		Summary:      types.Stats{Commits: 3, Users: 1, First: 1609459200, Last: 1609545600, UnknownShare: 0.25},
+		Repositories: []types.RankedRepository{{
+			Rank: 1, Repository: "repo|1", Score: 30,
+			Breakdown: []types.ScorePart{{Metric: "files", Weight: 10, Value: 3, Points: 30}},
+		}},
+	}
+
+	var b strings.Builder
+	if err := utils.MarkdownTemplate("", false).Execute(&b, report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| files | 10 | files changed |",
+		"- Window: 2021-01-01 00:00:00 to 2021-01-02 00:00:00 (UTC), 100 days analyzed",
+		"- Commits: 3 by 1 known users (25% by unknown authors)",
+		`| 1 | repo\|1 | 30 | files 30 |`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("default template output is missing %q:\n%s", want, b.String())
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "top.tmpl")
+	custom := "{{range .Repositories}}{{.Rank}}. {{.Repository}} ({{.Score}}) since {{date $.Summary.First $.Timezone}}\n{{end}}"
+	if err := os.WriteFile(path, []byte(custom), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b.Reset()
+	if err := utils.MarkdownTemplate(path, false).Execute(&b, report); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("1. repo|1 (30) since 2021-01-01 00:00:00\n", b.String()); diff != "" {
+		t.Errorf("custom template mismatch (-want +got):\n%s", diff)
+	}
+}
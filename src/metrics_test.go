@@ -0,0 +1,90 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FliCrz/blipper/src/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	testCases := []struct {
+		name    string
+		metrics []utils.Metric
+		want    string
+	}{
+		{
+			"Help and type lines",
+			[]utils.Metric{{Name: "blipper_up", Help: "Whether the last run succeeded.", Type: "gauge", Samples: []utils.Sample{{Value: 1}}}},
+			"# HELP blipper_up Whether the last run succeeded.\n# TYPE blipper_up gauge\nblipper_up 1\n",
+		},
+		{
+			"Sorted labels",
+			[]utils.Metric{{Name: "m", Help: "h", Type: "counter", Samples: []utils.Sample{
+				{Labels: map[string]string{"stage": "rank", "repository": "repo1", "a": "x"}, Value: 0.25},
+			}}},
+			"# HELP m h\n# TYPE m counter\n" + `m{a="x",repository="repo1",stage="rank"} 0.25` + "\n",
+		},
+		{
+			"Escaped label values",
+			[]utils.Metric{{Name: "m", Help: "h", Type: "gauge", Samples: []utils.Sample{
+				{Labels: map[string]string{"repository": "back\\slash \"quoted\"\nnext"}, Value: 3},
+			}}},
+			"# HELP m h\n# TYPE m gauge\n" + `m{repository="back\\slash \"quoted\"\nnext"} 3` + "\n",
+		},
+		{
+			"Metric without samples",
+			[]utils.Metric{{Name: "m", Help: "h", Type: "gauge"}},
+			"# HELP m h\n# TYPE m gauge\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := utils.WriteMetrics(&b, tc.metrics); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, b.String()); diff != "" {
+				t.Errorf("WriteMetrics() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteTextfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blipper.prom")
+	metrics := []utils.Metric{{Name: "m", Help: "h", Type: "gauge", Samples: []utils.Sample{{Value: 1}}}}
+	want := "# HELP m h\n# TYPE m gauge\nm 1\n"
+
+	utils.WriteTextfile(path, metrics, false)
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("WriteTextfile() mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary file to be renamed, stat error = %v", err)
+	}
+
+	// the file is only replaced once the temporary file is written, here it can't be created
+	if err := os.Mkdir(path+".tmp", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected WriteTextfile to fail when the temporary file can't be created")
+			}
+		}()
+		utils.WriteTextfile(path, []utils.Metric{{Name: "other", Help: "h", Type: "gauge"}}, false)
+	}()
+	if got, _ := os.ReadFile(path); string(got) != want {
+		t.Errorf("expected the previous file to be left untouched, got %q", got)
+	}
+}
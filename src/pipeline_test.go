@@ -0,0 +1,130 @@
+package main_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/FliCrz/blipper/src/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGroupByRepositoryParallel(t *testing.T) {
+	commits := syntheticCommits(10000, 50)
+	expected := utils.GroupByRepository(commits, false)
+	for _, workers := range []int{1, 2, 3, 8} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			got := utils.GroupByRepositoryParallel(commits, workers, false)
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("GroupByRepositoryParallel() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScoreRepositories(t *testing.T) {
+	repoMap := utils.GroupByRepository(syntheticCommits(1000, 20), false)
+	score := func(r *types.Repository) { r.Score = r.ScoreByFilter("commits") % 3 }
+	expected := utils.ScoreRepositories(repoMap, 1, score, false)
+	for n := 1; n < len(expected); n++ {
+		if expected[n-1].Repository >= expected[n].Repository {
+			t.Fatalf("ScoreRepositories() not ordered by name: %s before %s", expected[n-1].Repository, expected[n].Repository)
+		}
+	}
+	utils.SortByScore(expected)
+	for _, workers := range []int{2, 7} {
+		got := utils.ScoreRepositories(repoMap, workers, score, false)
+		utils.SortByScore(got)
+		if diff := cmp.Diff(expected, got); diff != "" {
+			t.Errorf("ScoreRepositories(%d workers) mismatch (-want +got):\n%s", workers, diff)
+		}
+	}
+}
+
+// syntheticCommits generates n reproducible commits spread over the given number of repositories.
+func syntheticCommits(n, repositories int) []types.Commit {
+	r := rand.New(rand.NewSource(1))
+	names := make([]string, repositories)
+	for i := range names {
+		names[i] = "repo" + strconv.Itoa(i)
+	}
+	commits := make([]types.Commit, n)
+	for i := range commits {
+		commits[i] = types.Commit{
+			Timestamp:  1607356800 + r.Int63n(100*24*60*60),
+			User:       "user" + strconv.Itoa(r.Intn(1000)),
+			Repository: names[r.Intn(repositories)],
+			Files:      r.Int63n(20),
+			Additions:  r.Int63n(500),
+			Deletions:  r.Int63n(200),
+		}
+	}
+	return commits
+}
+
+var (
+	benchOnce    sync.Once
+	benchCommits []types.Commit
+)
+
+// benchmarkCommits builds the benchmark dataset once, 10M rows unless BLIPPER_BENCH_ROWS says otherwise.
+func benchmarkCommits(b *testing.B) []types.Commit {
+	benchOnce.Do(func() {
+		rows := 10_000_000
+		if v := os.Getenv("BLIPPER_BENCH_ROWS"); v != "" {
+			rows = int(utils.ParseInt(v))
+		}
+		benchCommits = syntheticCommits(rows, 5000)
+	})
+	b.ResetTimer()
+	return benchCommits
+}
+
+func BenchmarkGroupByRepository(b *testing.B) {
+	commits := benchmarkCommits(b)
+	for i := 0; i < b.N; i++ {
+		utils.GroupByRepository(commits, false)
+	}
+}
+
+func BenchmarkGroupByRepositoryParallel(b *testing.B) {
+	commits := benchmarkCommits(b)
+	for _, workers := range []int{2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				utils.GroupByRepositoryParallel(commits, workers, false)
+			}
+		})
+	}
+}
+
+func BenchmarkAggregateByRepository(b *testing.B) {
+	commits := benchmarkCommits(b)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				utils.AggregateByRepository(commits, workers, 0, false)
+			}
+		})
+	}
+}
+
+func BenchmarkScoreRepositories(b *testing.B) {
+	repoMap := utils.GroupByRepository(benchmarkCommits(b), false)
+	score := func(r *types.Repository) {
+		for _, f := range []string{"files", "additions", "deletions", "users", "commits"} {
+			r.Score += r.ScoreByFilter(f)
+		}
+	}
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				utils.ScoreRepositories(repoMap, workers, score, false)
+			}
+		})
+	}
+}
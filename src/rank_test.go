@@ -0,0 +1,55 @@
+package main_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRankFewRepositories(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs the binary")
+	}
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "blipper")
+	if out, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+	csv := filepath.Join(dir, "commits.csv")
+	if err := os.WriteFile(csv, []byte(`timestamp,user,repository,files,additions,deletions
+1610969774,user1,repo1,1200,20,5
+1610969775,user2,repo2,5,10,2
+1610969776,user3,repo3,2,4,1
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty.csv")
+	if err := os.WriteFile(empty, []byte("timestamp,user,repository,files,additions,deletions\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"Included repositories", []string{"-f", csv, "--include-repo", "repo[12]"}, "\n[{repo1 12132 []} {repo2 169 []}]\n"},
+		{"Filtered commits", []string{"-f", csv, "--where", "files > 1000"}, "\n[{repo1 12132 []}]\n"},
+		{"Empty window", []string{"-f", csv, "--since", "2099-01-01"}, "\n[]\n"},
+		{"First incremental run on an empty file", []string{"-f", empty, "--incremental", filepath.Join(dir, "state.json")}, "\n[]\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := exec.Command(binary, append([]string{"rank"}, tc.args...)...).CombinedOutput()
+			if err != nil {
+				t.Fatalf("rank %v: %v\n%s", tc.args, err, out)
+			}
+			if !strings.Contains(string(out), tc.want) {
+				t.Errorf("rank %v output is missing %q:\n%s", tc.args, tc.want, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/FliCrz/blipper/src/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRepositoryFilter(t *testing.T) {
+	repos := []string{"payments-api", "payments-api-mirror", "payments-web", "search", "sandbox-1", "archived/legacy"}
+	testCases := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{"No patterns", nil, nil, repos},
+		{"Include glob", []string{"payments-*"}, nil, []string{"payments-api", "payments-api-mirror", "payments-web"}},
+		{"Exclude glob and regex", nil, []string{"sandbox-?", "/-mirror$/"}, []string{"payments-api", "payments-web", "search", "archived/legacy"}},
+		{"Exclude wins", []string{"/^payments-/", "search"}, []string{"*-mirror"}, []string{"payments-api", "payments-web", "search"}},
+		{"Glob stops at slashes", []string{"archived*"}, nil, nil},
+		{"Character class", []string{"sandbox-[0-9]", "archived/*"}, nil, []string{"sandbox-1", "archived/legacy"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := utils.NewRepositoryFilter(tc.include, tc.exclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var kept []string
+			for _, r := range repos {
+				if f.Keep(r) {
+					kept = append(kept, r)
+				}
+			}
+			if diff := cmp.Diff(tc.expected, kept); diff != "" {
+				t.Errorf("Keep() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, pattern := range []string{"[payments", "/(/"} {
+		if _, err := utils.NewRepositoryFilter(nil, []string{pattern}); err == nil {
+			t.Errorf("expected an error for pattern %s", pattern)
+		}
+	}
+}
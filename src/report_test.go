@@ -0,0 +1,64 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/FliCrz/blipper/src/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTopContributors(t *testing.T) {
+	r := types.Repository{Commits: []types.Commit{
+		{User: "user2"}, {User: "user1"}, {User: "user2"}, {User: "unknown"}, {User: "user3"}, {User: "user1"},
+	}}
+	expected := []types.ContributorCount{{User: "user1", Commits: 2}, {User: "user2", Commits: 2}, {User: "unknown", Commits: 1}}
+	if diff := cmp.Diff(expected, r.TopContributors(3)); diff != "" {
+		t.Errorf("TopContributors(3) mismatch (-want +got):\n%s", diff)
+	}
+	if got := len(r.TopContributors(10)); got != 4 {
+		t.Errorf("TopContributors(10) returned %d contributors, want 4", got)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	got := utils.Sparkline([]int64{0, 5, 10}, 100, 12)
+	if !strings.Contains(got, `points="0.0,11.0 50.0,6.0 100.0,1.0"`) {
+		t.Errorf("Sparkline() = %s", got)
+	}
+	if got := utils.Sparkline([]int64{0, 0}, 100, 12); !strings.Contains(got, `points="0.0,11.0 100.0,11.0"`) {
+		t.Errorf("Sparkline() of an idle series = %s", got)
+	}
+}
+
+func TestWriteHTMLReport(t *testing.T) {
+	report := types.Report{
+		Filename:  "commits.csv",
+		Timezone:  "UTC",
+		Algorithm: []types.ScorePart{{Metric: "files", Weight: 10}},
+		Summary:   types.Stats{Commits: 3},
+		Repositories: []types.RankedRepository{{
+			Rank: 1, Repository: "<repo1>", Score: 30,
+			Breakdown:       []types.ScorePart{{Metric: "files", Weight: 10, Value: 3, Points: 30}},
+			Weekly:          []types.Bucket{{Commits: 1}, {Commits: 2}},
+			TopContributors: []types.ContributorCount{{User: "user1", Commits: 3}},
+		}},
+	}
+	path := utils.WriteHTMLReport(filepath.Join(t.TempDir(), "out"), report, false)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(b)
+	for _, want := range []string{"&lt;repo1&gt;", "files: 3 x 10 = 30", "width: 100.00%", "<svg", "user1 (3)"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("WriteHTMLReport() output is missing %q", want)
+		}
+	}
+	if strings.Contains(html, "<repo1>") {
+		t.Errorf("WriteHTMLReport() didn't escape the repository name")
+	}
+}
@@ -0,0 +1,62 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		commit   types.Commit
+		expected string
+	}{
+		{"Small", types.Commit{Files: 1, Additions: 10, Deletions: 5}, "small"},
+		{"Medium by lines", types.Commit{Files: 1, Additions: 40, Deletions: 10}, "medium"},
+		{"Large by files", types.Commit{Files: 20, Additions: 1}, "large"},
+		{"Huge by lines", types.Commit{Files: 6, Additions: 999, Deletions: 1}, "huge"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.commit.Size(types.DefaultThresholds); got != tc.expected {
+				t.Errorf("Size() = %s, want %s", got, tc.expected)
+			}
+		})
+	}
+
+	r := types.Repository{Commits: []types.Commit{
+		testCases[0].commit, testCases[0].commit, testCases[1].commit, testCases[3].commit,
+	}}
+	sizes := r.Sizes(types.DefaultThresholds)
+	if diff := cmp.Diff(types.Sizes{Small: 2, Medium: 1, Huge: 1}, sizes); diff != "" {
+		t.Errorf("Sizes() mismatch (-want +got):\n%s", diff)
+	}
+	if flow := sizes.Flow(); flow != 12 {
+		t.Errorf("Flow() = %d, want 12", flow)
+	}
+}
+
+func TestQuantileThresholds(t *testing.T) {
+	var commits []types.Commit
+	for i := int64(1); i <= 101; i++ {
+		commits = append(commits, types.Commit{Files: i, Additions: i * 10})
+	}
+	expected := types.SizeThresholds{Files: [3]int64{52, 92, 101}, Lines: [3]int64{511, 911, 1001}}
+	if diff := cmp.Diff(expected, types.QuantileThresholds(commits)); diff != "" {
+		t.Errorf("QuantileThresholds() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseBounds(t *testing.T) {
+	if got, err := types.ParseBounds("1,2,3"); err != nil || got != [3]int64{1, 2, 3} {
+		t.Errorf("ParseBounds(1,2,3) = %v, %v", got, err)
+	}
+	for _, s := range []string{"1,2", "3,2,1", "a,b,c"} {
+		if _, err := types.ParseBounds(s); err == nil {
+			t.Errorf("ParseBounds(%s) expected an error", s)
+		}
+	}
+}
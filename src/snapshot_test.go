@@ -0,0 +1,61 @@
+package main_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/FliCrz/blipper/src/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSnapshotStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshots.jsonl")
+
+	if got := utils.ReadSnapshots(path, false); got != nil {
+		t.Fatalf("ReadSnapshots() on missing store = %v, want nil", got)
+	}
+
+	first := utils.AppendSnapshot(path, types.Snapshot{
+		Timestamp: 100,
+		Profile:   utils.ProfileHash("", "100"),
+		Repositories: []types.Repository{
+			{Repository: "repo1", Score: 10, Commits: []types.Commit{{Repository: "repo1"}}},
+			{Repository: "repo2", Score: 5},
+		},
+	}, false)
+	second := utils.AppendSnapshot(path, types.Snapshot{
+		Timestamp:    200,
+		Profile:      utils.ProfileHash("files", "100"),
+		Repositories: []types.Repository{{Repository: "repo2", Score: 7}},
+	}, false)
+
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("AppendSnapshot() ids = %d, %d, want 1, 2", first.ID, second.ID)
+	}
+	if first.Profile == second.Profile {
+		t.Errorf("ProfileHash() should differ between profiles, got %s", first.Profile)
+	}
+
+	snapshots := utils.ReadSnapshots(path, false)
+	if diff := cmp.Diff([]types.Snapshot{first, second}, snapshots); diff != "" {
+		t.Errorf("ReadSnapshots() mismatch (-want +got):\n%s", diff)
+	}
+	if snapshots[0].Repositories[0].Commits != nil {
+		t.Errorf("AppendSnapshot() should not store commits")
+	}
+
+	s := utils.FindSnapshot(snapshots, 1)
+	for _, tc := range []struct {
+		repository  string
+		rank, score int64
+	}{
+		{"repo1", 1, 10},
+		{"repo2", 2, 5},
+		{"repo3", 0, 0},
+	} {
+		if rank, score := s.Rank(tc.repository); rank != tc.rank || score != tc.score {
+			t.Errorf("Rank(%s) = %d, %d, want %d, %d", tc.repository, rank, score, tc.rank, tc.score)
+		}
+	}
+}
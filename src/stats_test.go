@@ -0,0 +1,49 @@
+package main_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStats(t *testing.T) {
+	day := int64(24 * 60 * 60)
+	monday := int64(1610928000)
+
+	testCases := []struct {
+		name       string
+		repository types.Repository
+		expected   types.Stats
+	}{
+		{
+			name: "Repository",
+			repository: types.Repository{Repository: "repo1", Commits: []types.Commit{
+				{Timestamp: monday + 10, User: "user1", Files: 1, Additions: 10, Deletions: 0},
+				{Timestamp: monday, User: "user2", Files: 3, Additions: 2, Deletions: 4},
+				{Timestamp: monday + day, User: "user1", Files: 2, Additions: 6, Deletions: 1},
+				{Timestamp: monday + 3*day, User: "unknown", Files: 10, Additions: 1, Deletions: 1},
+			}},
+			expected: types.Stats{
+				Repository: "repo1", Commits: 4, Users: 2, First: monday, Last: monday + 3*day,
+				Files: 16, Additions: 19, Deletions: 6,
+				MedianFiles: 2.5, MedianAdditions: 4, MedianDeletions: 1,
+				ActiveDays: 3, CommitsPerActiveDay: 4.0 / 3.0, UnknownShare: 0.25,
+			},
+		},
+		{
+			name:       "Empty",
+			repository: types.Repository{Repository: "repo2"},
+			expected:   types.Stats{Repository: "repo2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, tc.repository.Stats(time.UTC)); diff != "" {
+				t.Errorf("Stats() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package main_test
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/FliCrz/blipper/src/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadKey(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"Letters and enter", "js\r", []string{"j", "s", "enter"}},
+		{"Arrows", "\x1b[A\x1b[B\x1b[C\x1b[D", []string{"up", "down", "right", "left"}},
+		{"Application arrows", "\x1bOA", []string{"up"}},
+		{"Pages", "\x1b[5~\x1b[6~", []string{"pgup", "pgdown"}},
+		{"Lone escape", "\x1b", []string{"esc"}},
+		{"Unknown sequence", "\x1b[99~q", []string{"esc", "q"}},
+		{"Tab and backspace", "\t\x7f", []string{"tab", "backspace"}},
+		{"Ctrl+C", "\x03", []string{"ctrl+c"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tc.input))
+			var keys []string
+			for {
+				key, err := utils.ReadKey(r)
+				if err != nil {
+					break
+				}
+				keys = append(keys, key)
+			}
+			if diff := cmp.Diff(tc.expected, keys); diff != "" {
+				t.Errorf("ReadKey() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTextSparkline(t *testing.T) {
+	testCases := []struct {
+		name     string
+		values   []int64
+		expected string
+	}{
+		{"Scaled to the highest", []int64{0, 1, 7, 14}, "▁▂▄█"},
+		{"No activity", []int64{0, 0}, "▁▁"},
+		{"Empty", nil, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, utils.TextSparkline(tc.values)); diff != "" {
+				t.Errorf("TextSparkline() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
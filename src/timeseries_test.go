@@ -0,0 +1,139 @@
+package main_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/FliCrz/blipper/src/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBucketStart(t *testing.T) {
+	ts := int64(1610969774)                              // 2021-01-18 11:36:14 UTC, monday
+	sydney, err := time.LoadLocation("Australia/Sydney") // UTC+11, already 22:36 on monday
+	if err != nil {
+		t.Fatal(err)
+	}
+	losAngeles, err := time.LoadLocation("America/Los_Angeles") // UTC-8, still 03:36 on monday
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		name     string
+		interval string
+		loc      *time.Location
+		expected int64
+		wantErr  bool
+	}{
+		{"Day", "day", time.UTC, 1610928000, false},
+		{"Week", "week", time.UTC, 1610928000, false},
+		{"Month", "month", time.UTC, 1609459200, false},
+		{"Day in Sydney", "day", sydney, 1610928000 - 11*60*60, false},
+		{"Day in Los Angeles", "day", losAngeles, 1610928000 + 8*60*60, false},
+		{"Month in Sydney", "month", sydney, 1609459200 - 11*60*60, false},
+		{"Invalid interval", "year", time.UTC, 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := types.BucketStart(ts, tc.interval, tc.loc)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("BucketStart(%s) error = %v, wantErr %v", tc.interval, err, tc.wantErr)
+			}
+			if got != tc.expected {
+				t.Errorf("BucketStart(%s) = %d, want %d", tc.interval, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestTimeSeriesMidnightDST(t *testing.T) {
+	havana, err := time.LoadLocation("America/Havana") // DST starts on 2021-03-14, 00:00 becomes 01:00
+	if err != nil {
+		t.Fatal(err)
+	}
+	var commits []types.Commit
+	for d := 0; d < 10; d++ {
+		commits = append(commits, types.Commit{Timestamp: time.Date(2021, 3, 10+d, 12, 0, 0, 0, havana).Unix(), User: "user1"})
+	}
+	r := types.Repository{Repository: "repo1", Commits: commits}
+	testCases := []struct {
+		name     string
+		interval string
+		expected []int64
+	}{
+		{"Days", "day", []int64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}},
+		{"Weeks", "week", []int64{5, 5}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []int64
+			for _, b := range r.TimeSeries(tc.interval, commits[0].Timestamp, commits[9].Timestamp, havana) {
+				got = append(got, b.Commits)
+			}
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("TimeSeries(%s) mismatch (-want +got):\n%s", tc.interval, diff)
+			}
+		})
+	}
+}
+
+func TestTimeSeries(t *testing.T) {
+	day := int64(24 * 60 * 60)
+	first := int64(1610928000)
+	r := types.Repository{Commits: []types.Commit{
+		{Timestamp: first, User: "user1", Additions: 10, Deletions: 5},
+		{Timestamp: first + 1, User: "user2", Additions: 1},
+		{Timestamp: first + 2*day, User: "user1", Deletions: 3},
+	}}
+
+	testCases := []struct {
+		name     string
+		interval string
+		expected []types.Bucket
+	}{
+		{
+			name:     "Daily keeps empty buckets",
+			interval: "day",
+			expected: []types.Bucket{
+				{Start: first, Commits: 2, Churn: 16, Users: 2},
+				{Start: first + day},
+				{Start: first + 2*day, Commits: 1, Churn: 3, Users: 1},
+			},
+		},
+		{
+			name:     "Weekly",
+			interval: "week",
+			expected: []types.Bucket{
+				{Start: first, Commits: 3, Churn: 19, Users: 2},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := r.TimeSeries(tc.interval, first, first+2*day, time.UTC)
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("TimeSeries(%s) mismatch (-want +got):\n%s", tc.interval, diff)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := utils.ParseTime("2021-01-01", time.UTC); got != 1609459200 {
+		t.Errorf("ParseTime(2021-01-01, UTC) = %d, want 1609459200", got)
+	}
+	if got := utils.ParseTime("2021-01-01", tokyo); got != 1609459200-9*60*60 {
+		t.Errorf("ParseTime(2021-01-01, Asia/Tokyo) = %d, want %d", got, 1609459200-9*60*60)
+	}
+	if got := utils.ParseTime("1609459200", tokyo); got != 1609459200 {
+		t.Errorf("ParseTime(1609459200) = %d, want 1609459200", got)
+	}
+}
@@ -0,0 +1,67 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestNewTrend(t *testing.T) {
+	series := func(commits ...int64) (buckets []types.Bucket) {
+		for _, c := range commits {
+			buckets = append(buckets, types.Bucket{Commits: c})
+		}
+		return buckets
+	}
+
+	testCases := []struct {
+		name     string
+		buckets  []types.Bucket
+		window   int
+		expected types.Trend
+		momentum int64
+	}{
+		{
+			name:     "Steady",
+			buckets:  series(3, 3, 3, 3),
+			window:   2,
+			expected: types.Trend{Slope: 0, Ratio: 1, Acceleration: 0},
+			momentum: 0,
+		},
+		{
+			name:     "Rising",
+			buckets:  series(0, 1, 2, 3),
+			window:   2,
+			expected: types.Trend{Slope: 1, Ratio: 3, Acceleration: 0},
+			momentum: 168,
+		},
+		{
+			name:     "Declining",
+			buckets:  series(3, 2, 1, 0),
+			window:   2,
+			expected: types.Trend{Slope: -1, Ratio: 2.0 / 6.0, Acceleration: 0},
+			momentum: -168,
+		},
+		{
+			name:     "Window larger than half",
+			buckets:  series(0, 0, 0, 7),
+			window:   10,
+			expected: types.Trend{Slope: 2.1, Ratio: 8, Acceleration: 7},
+			momentum: 391,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := types.NewTrend(tc.buckets, tc.window)
+			if diff := cmp.Diff(tc.expected, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("NewTrend() mismatch (-want +got):\n%s", diff)
+			}
+			if m := got.Momentum(); m != tc.momentum {
+				t.Errorf("Momentum() = %d, want %d", m, tc.momentum)
+			}
+		})
+	}
+}
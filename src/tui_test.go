@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/FliCrz/blipper/src/types"
+	"github.com/FliCrz/blipper/src/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+// browserCommits gives repoN 10-2N commits spread over two years, repo0 being the most active.
+func browserCommits() (commits []types.Commit) {
+	for r := range 5 {
+		for n := range 10 - 2*r {
+			commits = append(commits, types.Commit{
+				Timestamp:  1607356800 + int64(n)*70*24*60*60,
+				User:       fmt.Sprintf("user%d", n%3),
+				Repository: fmt.Sprintf("repo%d", r),
+				Files:      int64(10 - 2*r),
+				Additions:  int64(100 - 20*r),
+				Deletions:  int64(10 - 2*r),
+			})
+		}
+	}
+	return commits
+}
+
+// newBrowser returns a browser over browserCommits, the weights it adjusts are restored after the test.
+func newBrowser(t *testing.T) *browser {
+	t.Helper()
+	saved := make([]int64, len(weights))
+	for n, w := range weights {
+		saved[n] = w.weight
+	}
+	t.Cleanup(func() {
+		for n := range weights {
+			weights[n].weight = saved[n]
+		}
+	})
+	b := &browser{all: browserCommits()}
+	b.refresh()
+	return b
+}
+
+type browserState struct {
+	Cursor  int
+	Window  int
+	Weight  int
+	Weights []int64
+	Detail  string
+	Scroll  int
+	Quit    bool
+}
+
+func TestBrowserHandle(t *testing.T) {
+	defaults := []int64{10, 1, 1, 5, 2}
+	testCases := []struct {
+		name     string
+		keys     []string
+		expected browserState
+	}{
+		{"Move down and up", []string{"j", "down", "k"}, browserState{Cursor: 1, Weights: defaults}},
+		{"Cursor stays in the list", []string{"k", "G", "j"}, browserState{Cursor: 4, Weights: defaults}},
+		{"Page down stops at the end", []string{"pgdown", "pgdown"}, browserState{Cursor: 4, Weights: defaults}},
+		{"Open and scroll", []string{"j", "enter", "j", "j"}, browserState{Cursor: 1, Detail: "repo1", Scroll: 2, Weights: defaults}},
+		{"Scroll stops at the last commit", []string{"enter", "G", "j"}, browserState{Detail: "repo0", Scroll: 9, Weights: defaults}},
+		{"Back to the list", []string{"j", "enter", "j", "esc"}, browserState{Cursor: 1, Scroll: 1, Weights: defaults}},
+		{"Next weight wraps", []string{"tab", "tab", "tab", "tab", "tab", "tab"}, browserState{Weight: 1, Weights: defaults}},
+		{"Raise a weight", []string{"+", "tab", "+"}, browserState{Weight: 1, Weights: []int64{11, 2, 1, 5, 2}}},
+		{"Lower a weight down to 0", []string{"tab", "-", "-"}, browserState{Weight: 1, Weights: []int64{10, 0, 1, 5, 2}}},
+		{"Narrow the window", []string{"[", "[", "]"}, browserState{Window: 1, Weights: defaults}},
+		{"Widest window", []string{"]"}, browserState{Weights: defaults}},
+		{"Window keeps the repository drilled into", []string{"enter", "["}, browserState{Window: 1, Detail: "repo0", Weights: defaults}},
+		{"Quit", []string{"j", "q"}, browserState{Cursor: 1, Weights: defaults, Quit: true}},
+		{"Quit on Ctrl+C", []string{"ctrl+c"}, browserState{Weights: defaults, Quit: true}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newBrowser(t)
+			var got browserState
+			for _, key := range tc.keys {
+				if got.Quit = b.handle(key, 24); got.Quit {
+					break
+				}
+			}
+			got.Cursor, got.Window, got.Weight, got.Scroll = b.cursor, b.window, b.weight, b.scroll
+			for _, w := range weights {
+				got.Weights = append(got.Weights, w.weight)
+			}
+			if b.detail != nil {
+				got.Detail = b.detail.Repository
+			}
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("handle(%q) mismatch (-want +got):\n%s", tc.keys, diff)
+			}
+		})
+	}
+}
+
+var escapes = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+func TestBrowserRender(t *testing.T) {
+	testCases := []struct {
+		name       string
+		keys       []string
+		rows, cols int
+		reversed   int // lines drawn in reverse video
+	}{
+		{"List", nil, 24, 80, 1},
+		{"Narrow list", []string{"j"}, 24, 30, 1},
+		{"Short list", []string{"G"}, 8, 80, 1},
+		{"Tiny list", []string{"j"}, 7, 80, 1},
+		{"Detail", []string{"enter"}, 24, 80, 0},
+		{"Narrow detail", []string{"enter"}, 12, 20, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newBrowser(t)
+			for _, key := range tc.keys {
+				b.handle(key, tc.rows)
+			}
+			lines := strings.Split(strings.TrimSuffix(b.render(tc.rows, tc.cols), "\n"), "\n")
+			if len(lines) > tc.rows {
+				t.Errorf("render(%d, %d) drew %d lines", tc.rows, tc.cols, len(lines))
+			}
+			reversed := 0
+			for n, l := range lines {
+				if w := len([]rune(escapes.ReplaceAllString(l, ""))); w > tc.cols {
+					t.Errorf("line %d is %d columns wide, want at most %d: %q", n, w, tc.cols, l)
+				}
+				if strings.Contains(l, utils.Reverse) {
+					reversed++
+				}
+				if (strings.Contains(l, utils.Reverse) || strings.Contains(l, utils.Bold)) && !strings.HasSuffix(l, utils.Reset) {
+					t.Errorf("line %d does not reset its attributes: %q", n, l)
+				}
+			}
+			if reversed != tc.reversed {
+				t.Errorf("render(%d, %d) reversed %d lines, want %d", tc.rows, tc.cols, reversed, tc.reversed)
+			}
+		})
+	}
+}
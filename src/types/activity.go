@@ -0,0 +1,69 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	workStart = 9  // working hours start, inclusive
+	workEnd   = 18 // working hours end, exclusive
+)
+
+// ActivityProfile ...
+type ActivityProfile struct {
+	Heatmap       [7][24]int64 `json:"heatmap"` // commits by weekday, sunday first, and hour
+	Commits       int64        `json:"commits"`
+	WeekendShare  float64      `json:"weekendShare"`
+	OffHoursShare float64      `json:"offHoursShare"` // weekday commits outside working hours
+}
+
+// NewActivityProfile builds the weekday by hour heatmap of commits in the given location.
+func NewActivityProfile(commits []Commit, loc *time.Location) ActivityProfile {
+	p := ActivityProfile{Commits: int64(len(commits))}
+	var weekend, offHours int64
+	for _, c := range commits {
+		t := time.Unix(c.Timestamp, 0).In(loc)
+		p.Heatmap[t.Weekday()][t.Hour()]++
+		switch {
+		case t.Weekday() == time.Saturday || t.Weekday() == time.Sunday:
+			weekend++
+		case t.Hour() < workStart || t.Hour() >= workEnd:
+			offHours++
+		}
+	}
+	if p.Commits > 0 {
+		p.WeekendShare = float64(weekend) / float64(p.Commits)
+		p.OffHoursShare = float64(offHours) / float64(p.Commits)
+	}
+	return p
+}
+
+// String renders the heatmap monday first, one character per hour scaled to the busiest hour.
+func (p ActivityProfile) String() string {
+	const shades = " .:-=+*#%@"
+	var busiest int64
+	for _, day := range p.Heatmap {
+		for _, n := range day {
+			busiest = max(busiest, n)
+		}
+	}
+	var b strings.Builder
+	b.WriteString("     0         1         2   \n")
+	b.WriteString("     012345678901234567890123\n")
+	for i := 1; i <= 7; i++ {
+		d := time.Weekday(i % 7)
+		fmt.Fprintf(&b, "%s  ", d.String()[:3])
+		for _, n := range p.Heatmap[d] {
+			s := 0
+			if n > 0 {
+				s = 1 + int(n*int64(len(shades)-2)/busiest)
+			}
+			b.WriteByte(shades[s])
+		}
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "commits: %d, weekend: %.0f%%, off-hours: %.0f%%\n", p.Commits, p.WeekendShare*100, p.OffHoursShare*100)
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package types
+
+import "fmt"
+
+// Aggregate holds the running totals of a repository scorers read from,
+// built in a single pass over its commits.
+type Aggregate struct {
+	Commits      int64               `json:"commits"`
+	Files        int64               `json:"files"`
+	Additions    int64               `json:"additions"`
+	Deletions    int64               `json:"deletions"`
+	TimestampSum int64               `json:"timestampSum"`
+	First        int64               `json:"first"`
+	Last         int64               `json:"last"`
+	Users        map[string]struct{} `json:"users,omitempty"`
+	Sketch       *HyperLogLog        `json:"sketch,omitempty"` // replaces Users once approximate counting kicks in
+	Precision    uint8               `json:"precision,omitempty"`
+}
+
+// exactUsers is how many distinct users are kept in a set before switching to a sketch,
+// small repositories stay exact and don't pay for the registers.
+const exactUsers = 64
+
+// NewAggregate counts distinct users exactly with a 0 precision,
+// otherwise approximately with a HyperLogLog of that precision.
+func NewAggregate(precision uint8) *Aggregate {
+	return &Aggregate{Users: make(map[string]struct{}), Precision: precision}
+}
+
+// Add accounts a commit.
+func (a *Aggregate) Add(c Commit) {
+	if a.Commits == 0 || c.Timestamp < a.First {
+		a.First = c.Timestamp
+	}
+	if a.Commits == 0 || c.Timestamp > a.Last {
+		a.Last = c.Timestamp
+	}
+	a.Commits++
+	a.Files += c.Files
+	a.Additions += c.Additions
+	a.Deletions += c.Deletions
+	a.TimestampSum += c.Timestamp
+	if a.Sketch != nil {
+		a.Sketch.Add(c.User)
+		return
+	}
+	a.Users[c.User] = struct{}{}
+	a.compact()
+}
+
+// compact switches to a sketch once there are too many users for an approximate aggregate.
+func (a *Aggregate) compact() {
+	if a.Precision == 0 || len(a.Users) <= exactUsers {
+		return
+	}
+	if a.Sketch == nil {
+		sketch, err := NewHyperLogLog(a.Precision)
+		if err != nil {
+			panic(err)
+		}
+		a.Sketch = sketch
+	}
+	for u := range a.Users {
+		a.Sketch.Add(u)
+	}
+	a.Users = nil
+}
+
+// Merge accounts the commits of another aggregate of the same repository.
+func (a *Aggregate) Merge(b *Aggregate) {
+	if b.Commits == 0 {
+		return
+	}
+	if a.Commits == 0 || b.First < a.First {
+		a.First = b.First
+	}
+	if a.Commits == 0 || b.Last > a.Last {
+		a.Last = b.Last
+	}
+	a.Commits += b.Commits
+	a.Files += b.Files
+	a.Additions += b.Additions
+	a.Deletions += b.Deletions
+	a.TimestampSum += b.TimestampSum
+	switch {
+	case a.Sketch != nil && b.Sketch != nil:
+		a.Sketch.Merge(b.Sketch)
+	case a.Sketch != nil:
+		for u := range b.Users {
+			a.Sketch.Add(u)
+		}
+	case b.Sketch != nil:
+		users := a.Users
+		a.Sketch, a.Users = b.Sketch.Clone(), nil
+		for u := range users {
+			a.Sketch.Add(u)
+		}
+	default:
+		if a.Users == nil {
+			a.Users = make(map[string]struct{})
+		}
+		for u := range b.Users {
+			a.Users[u] = struct{}{}
+		}
+		a.compact()
+	}
+}
+
+// DistinctUsers returns the number of distinct users, unknown authors counting as one.
+func (a *Aggregate) DistinctUsers() int64 {
+	if a.Sketch != nil {
+		return a.Sketch.Count()
+	}
+	return int64(len(a.Users))
+}
+
+// AggregateTargets are the targets answered from an Aggregate without the commits.
+var AggregateTargets = []string{"timestamp", "files", "additions", "deletions", "users", "commits", "distinctUsers"}
+
+// ScoreByFilter gives the same scores as Repository.ScoreByFilter,
+// "users" counting commits as it does, "distinctUsers" counting users.
+func (a *Aggregate) ScoreByFilter(f string) int64 {
+	switch f {
+	case "timestamp":
+		return a.TimestampSum
+	case "files":
+		return a.Files
+	case "additions":
+		return a.Additions
+	case "deletions":
+		return a.Deletions
+	case "users", "commits":
+		return a.Commits
+	case "distinctUsers":
+		return a.DistinctUsers()
+	default:
+		panic(fmt.Errorf("TypeError: unsupported type: %s", f))
+	}
+}
@@ -0,0 +1,53 @@
+package types
+
+import "math"
+
+// Alert ...
+type Alert struct {
+	Repository string  `json:"repository"`
+	Start      int64   `json:"start"` // start of the anomalous bucket
+	Kind       string  `json:"kind"`  // drop or spike
+	Commits    int64   `json:"commits"`
+	Mean       float64 `json:"mean"`
+	StdDev     float64 `json:"stddev"`
+	ZScore     float64 `json:"zscore"`
+	Threshold  float64 `json:"threshold"`
+}
+
+// DetectAnomalies compares the commits of each bucket with the rolling mean and standard deviation
+// of the baseline buckets before it, alerting when the z-score reaches the threshold.
+// The standard deviation is floored at 1 commit so a steady series doesn't alert on a single commit change.
+func DetectAnomalies(buckets []Bucket, baseline int, threshold float64) (alerts []Alert) {
+	if baseline < 2 {
+		baseline = 2
+	}
+	for i := baseline; i < len(buckets); i++ {
+		var mean, variance float64
+		for _, b := range buckets[i-baseline : i] {
+			mean += float64(b.Commits)
+		}
+		mean /= float64(baseline)
+		for _, b := range buckets[i-baseline : i] {
+			variance += math.Pow(float64(b.Commits)-mean, 2)
+		}
+		stddev := math.Max(math.Sqrt(variance/float64(baseline)), 1)
+		z := (float64(buckets[i].Commits) - mean) / stddev
+		if math.Abs(z) < threshold {
+			continue
+		}
+		kind := "spike"
+		if z < 0 {
+			kind = "drop"
+		}
+		alerts = append(alerts, Alert{
+			Start:     buckets[i].Start,
+			Kind:      kind,
+			Commits:   buckets[i].Commits,
+			Mean:      mean,
+			StdDev:    stddev,
+			ZScore:    z,
+			Threshold: threshold,
+		})
+	}
+	return alerts
+}
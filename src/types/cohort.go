@@ -0,0 +1,105 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// Contributor ...
+type Contributor struct {
+	User        string `json:"user"`
+	First       int64  `json:"first"`
+	Last        int64  `json:"last"`
+	Commits     int64  `json:"commits"`
+	ActiveWeeks int64  `json:"activeWeeks"`
+	Inactive    bool   `json:"inactive"` // no commit within the inactivity period before the end of the window
+}
+
+// Cohort ...
+type Cohort struct {
+	Week     int64   `json:"week"` // start of the week contributors first appeared in
+	Size     int64   `json:"size"`
+	Retained []int64 `json:"retained"` // members active n weeks after joining, index 0 being the joining week
+}
+
+// Contributors lists known authors sorted by first appearance,
+// flagging those without commits in the inactive seconds before last.
+func (r *Repository) Contributors(last, inactive int64, loc *time.Location) []Contributor {
+	index := make(map[string]int)
+	var contributors []Contributor
+	weeks := make(map[string]map[int64]bool)
+	for _, c := range r.Commits {
+		if c.User == "unknown" {
+			continue
+		}
+		n, ok := index[c.User]
+		if !ok {
+			n = len(contributors)
+			index[c.User] = n
+			contributors = append(contributors, Contributor{User: c.User, First: c.Timestamp, Last: c.Timestamp})
+			weeks[c.User] = make(map[int64]bool)
+		}
+		contributors[n].Commits++
+		contributors[n].First = min(contributors[n].First, c.Timestamp)
+		contributors[n].Last = max(contributors[n].Last, c.Timestamp)
+		w, _ := BucketStart(c.Timestamp, "week", loc)
+		weeks[c.User][w] = true
+	}
+	for n := range contributors {
+		contributors[n].ActiveWeeks = int64(len(weeks[contributors[n].User]))
+		contributors[n].Inactive = last-contributors[n].Last > inactive
+	}
+	sort.Slice(contributors, func(i, j int) bool {
+		if contributors[i].First != contributors[j].First {
+			return contributors[i].First < contributors[j].First
+		}
+		return contributors[i].User < contributors[j].User
+	})
+	return contributors
+}
+
+// Cohorts groups known authors by the week they first committed and counts,
+// for every following week up to last, how many of them were active.
+func (r *Repository) Cohorts(last int64, loc *time.Location) []Cohort {
+	lastWeek, _ := BucketStart(last, "week", loc)
+	joined := make(map[string]int64)
+	active := make(map[string]map[int64]bool)
+	for _, c := range r.Commits {
+		if c.User == "unknown" {
+			continue
+		}
+		w, _ := BucketStart(c.Timestamp, "week", loc)
+		if j, ok := joined[c.User]; !ok || w < j {
+			joined[c.User] = w
+		}
+		if active[c.User] == nil {
+			active[c.User] = make(map[int64]bool)
+		}
+		active[c.User][w] = true
+	}
+
+	cohortMap := make(map[int64]*Cohort)
+	for u, j := range joined {
+		cohort, ok := cohortMap[j]
+		if !ok {
+			cohort = &Cohort{Week: j}
+			for w := j; w <= lastWeek; w = NextBucket(w, "week", loc) {
+				cohort.Retained = append(cohort.Retained, 0)
+			}
+			cohortMap[j] = cohort
+		}
+		cohort.Size++
+		for n, w := 0, j; w <= lastWeek; n, w = n+1, NextBucket(w, "week", loc) {
+			if active[u][w] {
+				cohort.Retained[n]++
+			}
+		}
+	}
+
+	cohorts := make([]Cohort, 0, len(cohortMap))
+	for _, c := range cohortMap {
+		cohorts = append(cohorts, *c)
+	}
+	sort.Slice(cohorts, func(i, j int) bool { return cohorts[i].Week < cohorts[j].Week })
+	return cohorts
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Concentration ...
+type Concentration struct {
+	Authors   int64   `json:"authors"`
+	BusFactor int64   `json:"busFactor"` // minimum authors accounting for half of the contributions
+	Gini      float64 `json:"gini"`      // 0 evenly spread, 1 held by a single author
+	TopAuthor string  `json:"topAuthor"`
+	TopShare  float64 `json:"topShare"` // share of the contributions made by the top author
+}
+
+// Concentration measures how contributions, counted by commits or churn, are spread across authors.
+// Unknown authors are left out as they can't be told apart.
+func (r *Repository) Concentration(by string) Concentration {
+	perAuthor := make(map[string]int64)
+	for _, c := range r.Commits {
+		if c.User == "unknown" {
+			continue
+		}
+		switch by {
+		case "commits":
+			perAuthor[c.User]++
+		case "churn":
+			perAuthor[c.User] += c.Additions + c.Deletions
+		default:
+			panic(fmt.Errorf("TypeError: unsupported type: %s", by))
+		}
+	}
+	authors := make([]string, 0, len(perAuthor))
+	var total int64
+	for a, v := range perAuthor {
+		authors = append(authors, a)
+		total += v
+	}
+	// descending by contribution, then by name to stay deterministic
+	sort.Slice(authors, func(i, j int) bool {
+		if perAuthor[authors[i]] != perAuthor[authors[j]] {
+			return perAuthor[authors[i]] > perAuthor[authors[j]]
+		}
+		return authors[i] < authors[j]
+	})
+	c := Concentration{Authors: int64(len(authors))}
+	if total == 0 {
+		return c
+	}
+	c.TopAuthor = authors[0]
+	c.TopShare = float64(perAuthor[authors[0]]) / float64(total)
+
+	var cumulative int64
+	for _, a := range authors {
+		cumulative += perAuthor[a]
+		c.BusFactor++
+		if 2*cumulative >= total {
+			break
+		}
+	}
+
+	n := float64(len(authors))
+	if n == 1 {
+		c.Gini = 1
+		return c
+	}
+	var weighted float64
+	for i, a := range authors {
+		// rank in ascending order
+		weighted += (n - float64(i)) * float64(perAuthor[a])
+	}
+	// normalised by n/(n-1) so contributions held by one author reach 1 whatever the number of authors
+	c.Gini = (2*weighted/(n*float64(total)) - (n+1)/n) * n / (n - 1)
+	return c
+}
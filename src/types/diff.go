@@ -0,0 +1,13 @@
+package types
+
+// RankChange ...
+type RankChange struct {
+	Repository string `json:"repository"`
+	Status     string `json:"status"` // new, dropped, up, down or same
+	BaseRank   int64  `json:"baseRank"`
+	HeadRank   int64  `json:"headRank"`
+	Movement   int64  `json:"movement"` // positive when the repository climbed
+	BaseScore  int64  `json:"baseScore"`
+	HeadScore  int64  `json:"headScore"`
+	Delta      int64  `json:"delta"`
+}
@@ -0,0 +1,611 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression is a parsed expression over commit fields, checked when parsed so evaluating it cannot fail.
+// Metrics aggregate the commits of a repository, e.g. sum(additions + deletions) / count(),
+// filters select commits, e.g. user != "unknown" and repository ~ "^payments-".
+type Expression struct {
+	Source string
+	root   node
+}
+
+type kind int
+
+const (
+	number kind = iota
+	text
+	boolean
+)
+
+func (k kind) String() string {
+	return [...]string{"number", "string", "boolean"}[k]
+}
+
+type value struct {
+	num float64
+	str string
+	b   bool
+}
+
+type node interface {
+	kind() kind
+	// eval evaluates the node for a commit, aggregates evaluate over all the commits.
+	eval(c *Commit, commits []Commit) value
+}
+
+// textFields are the string fields of a commit, the numeric ones are read with Commit.GetValue.
+var textFields = map[string]func(c *Commit) string{
+	"user":       func(c *Commit) string { return c.User },
+	"repository": func(c *Commit) string { return c.Repository },
+}
+
+// aggregates compute a metric over the commits of a repository from an argument evaluated per commit.
+var aggregates = map[string]struct {
+	arg      []kind // accepted kinds of the argument, none when it takes no argument
+	optional bool
+}{
+	"count":    {arg: []kind{boolean}, optional: true},
+	"sum":      {arg: []kind{number}},
+	"avg":      {arg: []kind{number}},
+	"min":      {arg: []kind{number}},
+	"max":      {arg: []kind{number}},
+	"distinct": {arg: []kind{number, text, boolean}},
+}
+
+// ParseMetric parses an expression aggregating commits into a number.
+func ParseMetric(s string) (*Expression, error) {
+	p, err := newParser(s)
+	if err != nil {
+		return nil, err
+	}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if root.kind() != number {
+		return nil, fmt.Errorf("TypeError: metric %q is a %s, not a number", s, root.kind())
+	}
+	return &Expression{Source: s, root: root}, nil
+}
+
+// ParseFilter parses a boolean expression over the fields of a commit.
+func ParseFilter(s string) (*Expression, error) {
+	p, err := newParser(s)
+	if err != nil {
+		return nil, err
+	}
+	p.filter, p.aggregate = true, true
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if root.kind() != boolean {
+		return nil, fmt.Errorf("TypeError: filter %q is a %s, not a boolean", s, root.kind())
+	}
+	return &Expression{Source: s, root: root}, nil
+}
+
+// Match evaluates a filter expression for a commit.
+func (e *Expression) Match(c *Commit) bool {
+	return e.root.eval(c, nil).b
+}
+
+// Metric evaluates the expression over commits, rounded to an integer score.
+func (e *Expression) Metric(commits []Commit) int64 {
+	return int64(math.Round(e.root.eval(nil, commits).num))
+}
+
+// ScoreByExpression scores a repository with a metric expression.
+func (r *Repository) ScoreByExpression(e *Expression) int64 {
+	return e.Metric(r.Commits)
+}
+
+type token struct {
+	kind string // number, string, ident or the operator itself
+	text string
+	pos  int
+}
+
+var operators = []string{"==", "!=", "!~", "~", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "(", ")"}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			j := i
+			for j < len(s) && (unicode.IsDigit(rune(s[j])) || s[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{"number", s[i:j], i})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(s) && (unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j])) || s[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, token{"ident", s[i:j], i})
+			i = j
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("SyntaxError: unterminated string at %d", i)
+			}
+			str, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("SyntaxError: invalid string at %d: %w", i, err)
+			}
+			tokens = append(tokens, token{"string", str, i})
+			i = j + 1
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(s[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("SyntaxError: unexpected %q at %d", c, i)
+			}
+			tokens = append(tokens, token{op, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{"end", "", len(s)}), nil
+}
+
+type parser struct {
+	tokens    []token
+	pos       int
+	aggregate bool // within the argument of an aggregate, where commit fields are in scope
+	filter    bool // commit fields are in scope everywhere and aggregates are not available
+}
+
+func newParser(s string) (*parser, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{tokens: tokens}, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// keyword reports whether the next token is the word operator.
+func (p *parser) keyword(word string) bool {
+	t := p.peek()
+	return t.kind == "ident" && strings.EqualFold(t.text, word)
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != "end" {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind string) error {
+	if t := p.next(); t.kind != kind {
+		return p.unexpected(t)
+	}
+	return nil
+}
+
+func (p *parser) unexpected(t token) error {
+	if t.kind == "end" {
+		return fmt.Errorf("SyntaxError: unexpected end of expression")
+	}
+	return fmt.Errorf("SyntaxError: unexpected %q at %d", t.text, t.pos)
+}
+
+func (p *parser) parse() (node, error) {
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != "end" {
+		return nil, p.unexpected(t)
+	}
+	return n, nil
+}
+
+func (p *parser) or() (node, error) {
+	x, err := p.and()
+	for err == nil && p.keyword("or") {
+		p.next()
+		var y node
+		if y, err = p.and(); err == nil {
+			x, err = newBinary("or", x, y)
+		}
+	}
+	return x, err
+}
+
+func (p *parser) and() (node, error) {
+	x, err := p.not()
+	for err == nil && p.keyword("and") {
+		p.next()
+		var y node
+		if y, err = p.not(); err == nil {
+			x, err = newBinary("and", x, y)
+		}
+	}
+	return x, err
+}
+
+func (p *parser) not() (node, error) {
+	if !p.keyword("not") {
+		return p.comparison()
+	}
+	p.next()
+	x, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	return newUnary("not", x)
+}
+
+func (p *parser) comparison() (node, error) {
+	x, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	switch op := p.peek().kind; op {
+	case "~", "!~":
+		p.next()
+		t := p.next()
+		if t.kind != "string" {
+			return nil, fmt.Errorf("TypeError: %s expects a string pattern at %d", op, t.pos)
+		}
+		if x.kind() != text {
+			return nil, fmt.Errorf("TypeError: %s expects a string, not a %s", op, x.kind())
+		}
+		re, err := regexp.Compile(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("SyntaxError: invalid pattern at %d: %w", t.pos, err)
+		}
+		return match{x, re, op == "!~"}, nil
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+		y, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		return newBinary(op, x, y)
+	}
+	return x, nil
+}
+
+func (p *parser) additive() (node, error) {
+	x, err := p.multiplicative()
+	for err == nil && (p.peek().kind == "+" || p.peek().kind == "-") {
+		op := p.next().kind
+		var y node
+		if y, err = p.multiplicative(); err == nil {
+			x, err = newBinary(op, x, y)
+		}
+	}
+	return x, err
+}
+
+func (p *parser) multiplicative() (node, error) {
+	x, err := p.unary()
+	for err == nil && (p.peek().kind == "*" || p.peek().kind == "/" || p.peek().kind == "%") {
+		op := p.next().kind
+		var y node
+		if y, err = p.unary(); err == nil {
+			x, err = newBinary(op, x, y)
+		}
+	}
+	return x, err
+}
+
+func (p *parser) unary() (node, error) {
+	if p.peek().kind != "-" {
+		return p.primary()
+	}
+	p.next()
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return newUnary("-", x)
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case "number":
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("SyntaxError: invalid number %q at %d", t.text, t.pos)
+		}
+		return literal{value{num: v}, number}, nil
+	case "string":
+		return literal{value{str: t.text}, text}, nil
+	case "(":
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+	case "ident":
+		if p.peek().kind == "(" {
+			return p.call(t)
+		}
+		switch strings.ToLower(t.text) {
+		case "true", "false":
+			return literal{value{b: strings.EqualFold(t.text, "true")}, boolean}, nil
+		}
+		_, isText := textFields[t.text]
+		if _, err := (&Commit{}).GetValue(t.text); err != nil && !isText {
+			return nil, fmt.Errorf("TypeError: unsupported field: %s", t.text)
+		}
+		if !p.aggregate {
+			return nil, fmt.Errorf("TypeError: field %s is only available within an aggregate such as sum(%s)", t.text, t.text)
+		}
+		if isText {
+			return field{name: t.text, text: textFields[t.text]}, nil
+		}
+		return field{name: t.text}, nil
+	}
+	return nil, p.unexpected(t)
+}
+
+func (p *parser) call(name token) (node, error) {
+	spec, ok := aggregates[name.text]
+	if !ok {
+		return nil, fmt.Errorf("TypeError: unsupported function: %s", name.text)
+	}
+	if p.filter {
+		return nil, fmt.Errorf("TypeError: aggregate %s is not available in a filter", name.text)
+	}
+	if p.aggregate {
+		return nil, fmt.Errorf("TypeError: aggregate %s cannot be nested in another aggregate", name.text)
+	}
+	p.next()
+	if p.peek().kind == ")" && spec.optional {
+		p.next()
+		return aggregate{fn: name.text}, nil
+	}
+	p.aggregate = true
+	arg, err := p.or()
+	p.aggregate = false
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	for _, k := range spec.arg {
+		if arg.kind() == k {
+			return aggregate{fn: name.text, arg: arg}, nil
+		}
+	}
+	return nil, fmt.Errorf("TypeError: %s takes a %s, not a %s", name.text, spec.arg[0], arg.kind())
+}
+
+type literal struct {
+	v value
+	k kind
+}
+
+func (l literal) kind() kind                   { return l.k }
+func (l literal) eval(*Commit, []Commit) value { return l.v }
+
+type field struct {
+	name string
+	text func(c *Commit) string // set for string fields
+}
+
+func (f field) kind() kind {
+	if f.text != nil {
+		return text
+	}
+	return number
+}
+
+func (f field) eval(c *Commit, _ []Commit) value {
+	if f.text != nil {
+		return value{str: f.text(c)}
+	}
+	v, _ := c.GetValue(f.name)
+	return value{num: float64(v)}
+}
+
+type unary struct {
+	op string
+	x  node
+}
+
+func newUnary(op string, x node) (node, error) {
+	want := number
+	if op == "not" {
+		want = boolean
+	}
+	if x.kind() != want {
+		return nil, fmt.Errorf("TypeError: %s expects a %s, not a %s", op, want, x.kind())
+	}
+	return unary{op, x}, nil
+}
+
+func (u unary) kind() kind {
+	return u.x.kind()
+}
+
+func (u unary) eval(c *Commit, commits []Commit) value {
+	v := u.x.eval(c, commits)
+	if u.op == "not" {
+		return value{b: !v.b}
+	}
+	return value{num: -v.num}
+}
+
+type binary struct {
+	op   string
+	x, y node
+}
+
+func newBinary(op string, x, y node) (node, error) {
+	switch op {
+	case "and", "or":
+		if x.kind() != boolean || y.kind() != boolean {
+			return nil, fmt.Errorf("TypeError: %s expects booleans, not %s and %s", op, x.kind(), y.kind())
+		}
+	case "==", "!=", "<", "<=", ">", ">=":
+		if x.kind() != y.kind() || (x.kind() == boolean && op != "==" && op != "!=") {
+			return nil, fmt.Errorf("TypeError: cannot compare %s %s %s", x.kind(), op, y.kind())
+		}
+	default:
+		if x.kind() != number || y.kind() != number {
+			return nil, fmt.Errorf("TypeError: %s expects numbers, not %s and %s", op, x.kind(), y.kind())
+		}
+	}
+	return binary{op, x, y}, nil
+}
+
+func (b binary) kind() kind {
+	switch b.op {
+	case "+", "-", "*", "/", "%":
+		return number
+	}
+	return boolean
+}
+
+func (b binary) eval(c *Commit, commits []Commit) value {
+	x := b.x.eval(c, commits)
+	switch b.op {
+	case "and":
+		return value{b: x.b && b.y.eval(c, commits).b}
+	case "or":
+		return value{b: x.b || b.y.eval(c, commits).b}
+	}
+	y := b.y.eval(c, commits)
+	switch b.op {
+	case "+":
+		return value{num: x.num + y.num}
+	case "-":
+		return value{num: x.num - y.num}
+	case "*":
+		return value{num: x.num * y.num}
+	case "/", "%":
+		if y.num == 0 {
+			return value{} // an empty repository divides by zero, it scores 0
+		}
+		if b.op == "%" {
+			return value{num: math.Mod(x.num, y.num)}
+		}
+		return value{num: x.num / y.num}
+	}
+	var cmp int
+	switch b.x.kind() {
+	case text:
+		cmp = strings.Compare(x.str, y.str)
+	case boolean:
+		if x.b != y.b {
+			cmp = 1
+		}
+	default:
+		switch {
+		case x.num < y.num:
+			cmp = -1
+		case x.num > y.num:
+			cmp = 1
+		}
+	}
+	switch b.op {
+	case "==":
+		return value{b: cmp == 0}
+	case "!=":
+		return value{b: cmp != 0}
+	case "<":
+		return value{b: cmp < 0}
+	case "<=":
+		return value{b: cmp <= 0}
+	case ">":
+		return value{b: cmp > 0}
+	}
+	return value{b: cmp >= 0}
+}
+
+type match struct {
+	x      node
+	re     *regexp.Regexp
+	negate bool
+}
+
+func (m match) kind() kind {
+	return boolean
+}
+
+func (m match) eval(c *Commit, commits []Commit) value {
+	return value{b: m.re.MatchString(m.x.eval(c, commits).str) != m.negate}
+}
+
+type aggregate struct {
+	fn  string
+	arg node // nil for count()
+}
+
+func (a aggregate) kind() kind {
+	return number
+}
+
+func (a aggregate) eval(_ *Commit, commits []Commit) value {
+	if a.arg == nil {
+		return value{num: float64(len(commits))}
+	}
+	var result float64
+	seen := make(map[value]struct{})
+	for n := range commits {
+		v := a.arg.eval(&commits[n], commits)
+		switch a.fn {
+		case "count":
+			if v.b {
+				result++
+			}
+		case "sum", "avg":
+			result += v.num
+		case "min":
+			if n == 0 || v.num < result {
+				result = v.num
+			}
+		case "max":
+			if n == 0 || v.num > result {
+				result = v.num
+			}
+		case "distinct":
+			seen[v] = struct{}{}
+		}
+	}
+	switch a.fn {
+	case "avg":
+		if len(commits) > 0 {
+			result /= float64(len(commits))
+		}
+	case "distinct":
+		result = float64(len(seen))
+	}
+	return value{num: result}
+}
@@ -0,0 +1,102 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// HyperLogLog estimates distinct counts in 2^precision registers,
+// with a standard error of about 1.04 / sqrt(2^precision).
+type HyperLogLog struct {
+	precision uint8
+	registers []uint8
+}
+
+// NewHyperLogLog accepts precisions from 4 to 16.
+func NewHyperLogLog(precision uint8) (*HyperLogLog, error) {
+	if precision < 4 || precision > 16 {
+		return nil, fmt.Errorf("invalid precision %d: must be between 4 and 16", precision)
+	}
+	return &HyperLogLog{precision: precision, registers: make([]uint8, 1<<precision)}, nil
+}
+
+// hash64 is FNV-1a followed by the splitmix64 finalizer so every bit is well mixed.
+func hash64(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Merge keeps the highest register values, both sketches must share the precision.
+func (h *HyperLogLog) Merge(o *HyperLogLog) {
+	if h.precision != o.precision {
+		panic(fmt.Errorf("cannot merge sketches of precision %d and %d", h.precision, o.precision))
+	}
+	for n, r := range o.registers {
+		h.registers[n] = max(h.registers[n], r)
+	}
+}
+
+// Clone ...
+func (h *HyperLogLog) Clone() *HyperLogLog {
+	return &HyperLogLog{precision: h.precision, registers: slices.Clone(h.registers)}
+}
+
+type hyperLogLogJSON struct {
+	Precision uint8  `json:"precision"`
+	Registers []byte `json:"registers"`
+}
+
+func (h *HyperLogLog) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hyperLogLogJSON{h.precision, h.registers})
+}
+
+func (h *HyperLogLog) UnmarshalJSON(b []byte) error {
+	var v hyperLogLogJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v.Precision < 4 || v.Precision > 16 || len(v.Registers) != 1<<v.Precision {
+		return fmt.Errorf("invalid sketch of precision %d with %d registers", v.Precision, len(v.Registers))
+	}
+	h.precision, h.registers = v.Precision, v.Registers
+	return nil
+}
+
+// Add ...
+func (h *HyperLogLog) Add(s string) {
+	x := hash64(s)
+	n := x >> (64 - h.precision)
+	rank := uint8(bits.LeadingZeros64(x<<h.precision|1<<(h.precision-1))) + 1
+	if rank > h.registers[n] {
+		h.registers[n] = rank
+	}
+}
+
+// Count returns the estimate, using linear counting for small cardinalities.
+func (h *HyperLogLog) Count() int64 {
+	m := float64(len(h.registers))
+	var sum float64
+	var zeros int
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int64(math.Round(estimate))
+}
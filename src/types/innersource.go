@@ -0,0 +1,85 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// InnerSource ...
+type InnerSource struct {
+	OwnerTeam         string `json:"ownerTeam"`
+	ExternalTeams     int64  `json:"externalTeams"`
+	ExternalShare     int64  `json:"externalShare"`     // percentage of commits made by external teams
+	ExternalRetention int64  `json:"externalRetention"` // percentage of external users active in more than one week
+}
+
+// InnerSource computes cross-team metrics given a user to team mapping, weeks starting in the given location.
+// The owner team is the team with most commits, users without a team are ignored.
+func (r *Repository) InnerSource(teams map[string]string, loc *time.Location) InnerSource {
+	perTeam := make(map[string]int64)
+	for _, c := range r.Commits {
+		if t, ok := teams[c.User]; ok {
+			perTeam[t]++
+		}
+	}
+	names := make([]string, 0, len(perTeam))
+	for t := range perTeam {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+	is := InnerSource{}
+	for _, t := range names {
+		if is.OwnerTeam == "" || perTeam[t] > perTeam[is.OwnerTeam] {
+			is.OwnerTeam = t
+		}
+	}
+	if is.OwnerTeam == "" {
+		return is
+	}
+
+	var external, mapped int64
+	weeks := make(map[string]map[int64]bool)
+	for _, c := range r.Commits {
+		t, ok := teams[c.User]
+		if !ok {
+			continue
+		}
+		mapped++
+		if t == is.OwnerTeam {
+			continue
+		}
+		external++
+		if weeks[c.User] == nil {
+			weeks[c.User] = make(map[int64]bool)
+		}
+		w, _ := BucketStart(c.Timestamp, "week", loc)
+		weeks[c.User][w] = true
+	}
+	is.ExternalTeams = int64(len(perTeam) - 1)
+	is.ExternalShare = external * 100 / mapped
+	if len(weeks) > 0 {
+		var retained int64
+		for _, w := range weeks {
+			if len(w) > 1 {
+				retained++
+			}
+		}
+		is.ExternalRetention = retained * 100 / int64(len(weeks))
+	}
+	return is
+}
+
+func (r *Repository) ScoreByInnerSource(f string, teams map[string]string, loc *time.Location) int64 {
+	is := r.InnerSource(teams, loc)
+	switch f {
+	case "externalTeams":
+		return is.ExternalTeams
+	case "externalShare":
+		return is.ExternalShare
+	case "externalRetention":
+		return is.ExternalRetention
+	default:
+		panic(fmt.Errorf("TypeError: unsupported type: %s", f))
+	}
+}
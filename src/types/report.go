@@ -0,0 +1,59 @@
+package types
+
+import "sort"
+
+// ScorePart is the share of a repository score coming from one metric.
+type ScorePart struct {
+	Metric      string `json:"metric"`
+	Description string `json:"description,omitempty"`
+	Weight      int64  `json:"weight"`
+	Value       int64  `json:"value"`
+	Points      int64  `json:"points"` // value * weight
+}
+
+// ContributorCount ...
+type ContributorCount struct {
+	User    string `json:"user"`
+	Commits int64  `json:"commits"`
+}
+
+// RankedRepository ...
+type RankedRepository struct {
+	Rank            int64              `json:"rank"`
+	Repository      string             `json:"repository"`
+	Score           int64              `json:"score"`
+	Breakdown       []ScorePart        `json:"breakdown"`
+	Weekly          []Bucket           `json:"weekly"`
+	TopContributors []ContributorCount `json:"topContributors"`
+}
+
+// Report ...
+type Report struct {
+	Generated    int64              `json:"generated"`
+	Filename     string             `json:"filename"`
+	Target       string             `json:"target"`    // empty for the default algorythm
+	Algorithm    []ScorePart        `json:"algorithm"` // metrics and weights, without values
+	NumberOfDays int64              `json:"numberOfDays"`
+	Timezone     string             `json:"timezone"`
+	Summary      Stats              `json:"summary"`
+	Repositories []RankedRepository `json:"repositories"`
+}
+
+// TopContributors returns the n users with most commits, unknown authors included.
+func (r *Repository) TopContributors(n int) []ContributorCount {
+	perUser := make(map[string]int64)
+	for _, c := range r.Commits {
+		perUser[c.User]++
+	}
+	counts := make([]ContributorCount, 0, len(perUser))
+	for u, c := range perUser {
+		counts = append(counts, ContributorCount{u, c})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Commits != counts[j].Commits {
+			return counts[i].Commits > counts[j].Commits
+		}
+		return counts[i].User < counts[j].User
+	})
+	return counts[:min(n, len(counts))]
+}
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SizeThresholds holds the lower bounds of the medium, large and huge classes.
+type SizeThresholds struct {
+	Files [3]int64 `json:"files"`
+	Lines [3]int64 `json:"lines"` // additions + deletions
+}
+
+// Sizes ...
+type Sizes struct {
+	Small  int64 `json:"small"`
+	Medium int64 `json:"medium"`
+	Large  int64 `json:"large"`
+	Huge   int64 `json:"huge"`
+}
+
+var (
+	sizeClasses       = []string{"small", "medium", "large", "huge"}
+	DefaultThresholds = SizeThresholds{Files: [3]int64{5, 20, 100}, Lines: [3]int64{50, 250, 1000}}
+)
+
+func percentile(values []int64, p float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values[int(p*float64(len(values)-1))]
+}
+
+// QuantileThresholds derives thresholds from the 50th, 90th and 99th percentiles of the commits,
+// a commit must exceed the percentile to move up a class.
+func QuantileThresholds(commits []Commit) SizeThresholds {
+	files := make([]int64, len(commits))
+	lines := make([]int64, len(commits))
+	for n, c := range commits {
+		files[n] = c.Files
+		lines[n] = c.Additions + c.Deletions
+	}
+	t := SizeThresholds{}
+	for n, p := range []float64{0.5, 0.9, 0.99} {
+		t.Files[n] = percentile(files, p) + 1
+		t.Lines[n] = percentile(lines, p) + 1
+	}
+	return t
+}
+
+func class(v int64, bounds [3]int64) int {
+	n := 0
+	for n < len(bounds) && v >= bounds[n] {
+		n++
+	}
+	return n
+}
+
+// Size classifies the commit as small, medium, large or huge,
+// taking the largest class reached by either its files or its lines changed.
+func (c *Commit) Size(t SizeThresholds) string {
+	return sizeClasses[max(class(c.Files, t.Files), class(c.Additions+c.Deletions, t.Lines))]
+}
+
+// Sizes counts the repository commits per size class.
+func (r *Repository) Sizes(t SizeThresholds) Sizes {
+	s := Sizes{}
+	for _, c := range r.Commits {
+		switch c.Size(t) {
+		case "small":
+			s.Small++
+		case "medium":
+			s.Medium++
+		case "large":
+			s.Large++
+		default:
+			s.Huge++
+		}
+	}
+	return s
+}
+
+// Flow weights commits by size, small 4, medium 3, large 2 and huge 1,
+// so many small commits score higher than few huge ones.
+func (s Sizes) Flow() int64 {
+	return 4*s.Small + 3*s.Medium + 2*s.Large + s.Huge
+}
+
+// ParseBounds parses comma separated medium,large,huge lower bounds.
+func ParseBounds(s string) ([3]int64, error) {
+	var b [3]int64
+	if _, err := fmt.Sscanf(s, "%d,%d,%d", &b[0], &b[1], &b[2]); err != nil {
+		return b, fmt.Errorf("invalid size bounds %q: %w", s, err)
+	}
+	if b[0] > b[1] || b[1] > b[2] {
+		return b, fmt.Errorf("invalid size bounds %q: must be increasing", s)
+	}
+	return b, nil
+}
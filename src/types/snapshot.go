@@ -0,0 +1,20 @@
+package types
+
+// Snapshot ...
+type Snapshot struct {
+	ID           int64        `json:"id"`
+	Timestamp    int64        `json:"timestamp"` // when the run happened
+	Profile      string       `json:"profile"`   // hash of the scoring options
+	Filename     string       `json:"filename"`
+	Repositories []Repository `json:"repositories"` // sorted by score, without commits
+}
+
+// Rank returns the 1-based rank and the score of a repository, 0 rank if not present.
+func (s *Snapshot) Rank(repository string) (int64, int64) {
+	for n, r := range s.Repositories {
+		if r.Repository == repository {
+			return int64(n + 1), r.Score
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,9 @@
+package types
+
+// State ...
+type State struct {
+	First        int64                 `json:"first"`   // first commit ever processed
+	Last         int64                 `json:"last"`    // last commit processed, newer ones are ingested next
+	Options      string                `json:"options"` // profile of the ingest options the aggregates were built with
+	Repositories map[string]*Aggregate `json:"repositories"`
+}
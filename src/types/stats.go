@@ -0,0 +1,76 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// Stats ...
+type Stats struct {
+	Repository          string  `json:"repository"`
+	Commits             int64   `json:"commits"`
+	Users               int64   `json:"users"` // distinct known users
+	First               int64   `json:"first"`
+	Last                int64   `json:"last"`
+	Files               int64   `json:"files"`
+	Additions           int64   `json:"additions"`
+	Deletions           int64   `json:"deletions"`
+	MedianFiles         float64 `json:"medianFiles"`
+	MedianAdditions     float64 `json:"medianAdditions"`
+	MedianDeletions     float64 `json:"medianDeletions"`
+	ActiveDays          int64   `json:"activeDays"`
+	CommitsPerActiveDay float64 `json:"commitsPerActiveDay"`
+	UnknownShare        float64 `json:"unknownShare"` // share of commits without a known author
+}
+
+func median(values []int64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	m := len(values) / 2
+	if len(values)%2 == 0 {
+		return float64(values[m-1]+values[m]) / 2
+	}
+	return float64(values[m])
+}
+
+// Stats summarises the repository commits, active days being counted in the given location.
+func (r *Repository) Stats(loc *time.Location) Stats {
+	s := Stats{Repository: r.Repository, Commits: int64(len(r.Commits))}
+	if s.Commits == 0 {
+		return s
+	}
+	users := make(map[string]bool)
+	days := make(map[int64]bool)
+	files := make([]int64, 0, len(r.Commits))
+	additions := make([]int64, 0, len(r.Commits))
+	deletions := make([]int64, 0, len(r.Commits))
+	var unknown int64
+	s.First, s.Last = r.Commits[0].Timestamp, r.Commits[0].Timestamp
+	for _, c := range r.Commits {
+		if c.User == "unknown" {
+			unknown++
+		} else {
+			users[c.User] = true
+		}
+		d, _ := BucketStart(c.Timestamp, "day", loc)
+		days[d] = true
+		s.First = min(s.First, c.Timestamp)
+		s.Last = max(s.Last, c.Timestamp)
+		s.Files += c.Files
+		s.Additions += c.Additions
+		s.Deletions += c.Deletions
+		files = append(files, c.Files)
+		additions = append(additions, c.Additions)
+		deletions = append(deletions, c.Deletions)
+	}
+	s.Users = int64(len(users))
+	s.MedianFiles = median(files)
+	s.MedianAdditions = median(additions)
+	s.MedianDeletions = median(deletions)
+	s.ActiveDays = int64(len(days))
+	s.CommitsPerActiveDay = float64(s.Commits) / float64(s.ActiveDays)
+	s.UnknownShare = float64(unknown) / float64(s.Commits)
+	return s
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// Bucket ...
+type Bucket struct {
+	Start   int64 `json:"start"`
+	Commits int64 `json:"commits"`
+	Churn   int64 `json:"churn"` // additions + deletions
+	Users   int64 `json:"users"`
+}
+
+// BucketStart truncates a timestamp to the start of its day, week (monday) or month in the given location.
+func BucketStart(ts int64, interval string, loc *time.Location) (int64, error) {
+	t := time.Unix(ts, 0).In(loc)
+	switch interval {
+	case "day":
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case "week":
+		t = time.Date(t.Year(), t.Month(), t.Day()-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case "month":
+		t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return 0, fmt.Errorf("TypeError: unsupported interval: %s", interval)
+	}
+	return t.Unix(), nil
+}
+
+// NextBucket returns the start of the bucket following the one starting at start. It truncates a time
+// within the next period rather than adding calendar days, where DST changes at midnight the start of a day
+// isn't midnight and stepping from it would drift off the grid of BucketStart.
+func NextBucket(start int64, interval string, loc *time.Location) int64 {
+	step := map[string]int64{"day": 36, "week": 8 * 24, "month": 32 * 24}[interval]
+	next, err := BucketStart(start+step*60*60, interval, loc)
+	if err != nil {
+		panic(err)
+	}
+	return next
+}
+
+// TimeSeries buckets the repository commits between first and last timestamps,
+// empty buckets are kept so series of different repositories line up.
+func (r *Repository) TimeSeries(interval string, first, last int64, loc *time.Location) []Bucket {
+	from, err := BucketStart(first, interval, loc)
+	if err != nil {
+		panic(err)
+	}
+	var buckets []Bucket
+	index := make(map[int64]int)
+	for s := from; s <= last; s = NextBucket(s, interval, loc) {
+		index[s] = len(buckets)
+		buckets = append(buckets, Bucket{Start: s})
+	}
+	users := make([]map[string]bool, len(buckets))
+	for _, c := range r.Commits {
+		s, _ := BucketStart(c.Timestamp, interval, loc)
+		n, ok := index[s]
+		if !ok {
+			continue
+		}
+		buckets[n].Commits++
+		buckets[n].Churn += c.Additions + c.Deletions
+		if users[n] == nil {
+			users[n] = make(map[string]bool)
+		}
+		users[n][c.User] = true
+	}
+	for n := range buckets {
+		buckets[n].Users = int64(len(users[n]))
+	}
+	return buckets
+}
@@ -0,0 +1,51 @@
+package types
+
+import "math"
+
+// Trend ...
+type Trend struct {
+	Slope        float64 `json:"slope"`        // commits per bucket from a linear regression
+	Ratio        float64 `json:"ratio"`        // recent window commits / prior window commits
+	Acceleration float64 `json:"acceleration"` // slope of the recent half minus slope of the prior half
+}
+
+func slope(buckets []Bucket) float64 {
+	n := float64(len(buckets))
+	if n < 2 {
+		return 0
+	}
+	var sx, sy, sxy, sxx float64
+	for i, b := range buckets {
+		x, y := float64(i), float64(b.Commits)
+		sx += x
+		sy += y
+		sxy += x * y
+		sxx += x * x
+	}
+	return (n*sxy - sx*sy) / (n*sxx - sx*sx)
+}
+
+// NewTrend computes trend metrics over buckets, comparing the last window buckets with the window before.
+func NewTrend(buckets []Bucket, window int) Trend {
+	if window <= 0 || window > len(buckets)/2 {
+		window = len(buckets) / 2
+	}
+	var recent, prior float64
+	for i := len(buckets) - window; i < len(buckets); i++ {
+		recent += float64(buckets[i].Commits)
+	}
+	for i := len(buckets) - 2*window; i < len(buckets)-window; i++ {
+		prior += float64(buckets[i].Commits)
+	}
+	half := len(buckets) / 2
+	return Trend{
+		Slope:        slope(buckets),
+		Ratio:        (recent + 1) / (prior + 1), // smoothed so silent windows don't divide by zero
+		Acceleration: slope(buckets[half:]) - slope(buckets[:half]),
+	}
+}
+
+// Momentum combines the trend metrics into a single score, negative for declining repositories.
+func (t Trend) Momentum() int64 {
+	return int64(math.Round(10*t.Slope + 100*math.Log2(t.Ratio) + 10*t.Acceleration))
+}
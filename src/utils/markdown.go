@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// DefaultMarkdownTemplate renders a report as the documentation hand-off asks: the algorithm,
+// the dataset window and the ranked repositories.
+const DefaultMarkdownTemplate = `# Repository Activity Score
+
+## Algorithm
+{{if .Target}}Repositories are scored by the ` + "`{{.Target}}`" + ` target.
+{{else}}Each repository score is the sum of the following metrics multiplied by their weight.
+{{end}}
+| Metric | Weight | Description |
+| ------ | ------ | ----------- |
+{{range .Algorithm}}| {{cell .Metric}} | {{.Weight}} | {{cell .Description}} |
+{{end}}
+## Dataset
+- File: {{cell .Filename}}
+- Window: {{date .Summary.First .Timezone}} to {{date .Summary.Last .Timezone}} ({{.Timezone}}), {{.NumberOfDays}} days analyzed
+- Commits: {{.Summary.Commits}} by {{.Summary.Users}} known users ({{percent .Summary.UnknownShare}} by unknown authors)
+
+## Top {{len .Repositories}} Repositories
+| Rank | Repository | Score | Breakdown |
+| ---- | ---------- | ----- | --------- |
+{{range .Repositories}}| {{.Rank}} | {{cell .Repository}} | {{.Score}} | {{range $n, $p := .Breakdown}}{{if $n}}, {{end}}{{cell $p.Metric}} {{$p.Points}}{{end}} |
+{{end}}`
+
+var markdownFuncs = templateFuncs(template.FuncMap{
+	// cell escapes pipes and line breaks so values stay within a table cell
+	"cell": strings.NewReplacer("|", `\|`, "\n", " ").Replace,
+})
+
+// MarkdownTemplate parses the template file at path, or the default template when path is empty.
+// Templates receive a types.Report and the date, percent and cell functions.
+func MarkdownTemplate(path string, debug bool) *template.Template {
+	text := DefaultMarkdownTemplate
+	if path != "" {
+		Debugger(fmt.Sprintf("reading template: %s", path), debug)
+		b, err := os.ReadFile(path)
+		ErrorLogger(err)
+		text = string(b)
+	}
+	tmpl, err := template.New("markdown").Funcs(markdownFuncs).Parse(text)
+	ErrorLogger(err)
+	return tmpl
+}
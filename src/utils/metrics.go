@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sample ...
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// Metric ...
+type Metric struct {
+	Name    string
+	Help    string
+	Type    string // gauge or counter
+	Samples []Sample
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// WriteMetrics writes metrics in the Prometheus text exposition format.
+func WriteMetrics(w io.Writer, metrics []Metric) error {
+	for _, m := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.Name, m.Help, m.Name, m.Type); err != nil {
+			return err
+		}
+		for _, s := range m.Samples {
+			names := make([]string, 0, len(s.Labels))
+			for k := range s.Labels {
+				names = append(names, k)
+			}
+			sort.Strings(names)
+			labels := make([]string, len(names))
+			for n, k := range names {
+				labels[n] = fmt.Sprintf(`%s="%s"`, k, labelEscaper.Replace(s.Labels[k]))
+			}
+			line := m.Name
+			if len(labels) > 0 {
+				line += "{" + strings.Join(labels, ",") + "}"
+			}
+			if _, err := fmt.Fprintf(w, "%s %s\n", line, strconv.FormatFloat(s.Value, 'f', -1, 64)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteTextfile writes metrics for the node_exporter textfile collector,
+// through a temporary file so the collector never reads a partial file.
+func WriteTextfile(path string, metrics []Metric, debug bool) {
+	Debugger(fmt.Sprintf("writing metrics: %s", path), debug)
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	ErrorLogger(err)
+	ErrorLogger(WriteMetrics(f, metrics))
+	ErrorLogger(f.Close())
+	ErrorLogger(os.Rename(tmp, path))
+}
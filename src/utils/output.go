@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+)
+
+// templateFuncs returns the functions shared by the report templates merged with the given ones.
+func templateFuncs(funcs template.FuncMap) template.FuncMap {
+	shared := template.FuncMap{
+		"date": func(ts int64, tz string) string {
+			loc, err := time.LoadLocation(tz)
+			if err != nil {
+				loc = time.UTC
+			}
+			return time.Unix(ts, 0).In(loc).Format(time.DateTime)
+		},
+		"percent": func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) },
+	}
+	maps.Copy(shared, funcs)
+	return shared
+}
+
+// Print writes v as indented JSON when format is "json",
+// otherwise writes rows (header first) as CSV or as an aligned table.
+func Print(format string, v any, rows [][]string) {
+	switch format {
+	case "json":
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		ErrorLogger(e.Encode(v))
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		ErrorLogger(w.WriteAll(rows))
+	case "table", "":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, r := range rows {
+			fmt.Fprintln(w, strings.Join(r, "\t"))
+		}
+		ErrorLogger(w.Flush())
+	default:
+		ErrorLogger(fmt.Errorf("TypeError: unsupported format: %s", format))
+	}
+}
+
+// WriteJSON writes v as indented JSON to a file.
+func WriteJSON(path string, v any, debug bool) {
+	Debugger(fmt.Sprintf("writing file: %s", path), debug)
+	b, err := json.MarshalIndent(v, "", "  ")
+	ErrorLogger(err)
+	ErrorLogger(os.WriteFile(path, append(b, '\n'), 0o644))
+}
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/FliCrz/blipper/src/types"
+)
+
+// chunks calls f concurrently on contiguous ranges of n items, one per worker.
+func chunks(n, workers int, f func(w, from, to int)) {
+	var wg sync.WaitGroup
+	size := (n + workers - 1) / workers
+	for w := 0; w < workers; w++ {
+		from, to := min(w*size, n), min((w+1)*size, n)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f(w, from, to)
+		}()
+	}
+	wg.Wait()
+}
+
+// shardIndex assigns concurrently each commit to the shard owning its repository, then buckets the commit
+// indices by shard with a counting sort, in input order, so each worker only visits the commits of its shard.
+func shardIndex(c []types.Commit, workers int) [][]int {
+	shards := make([]int32, len(c))
+	chunks(len(c), workers, func(_, from, to int) {
+		h := fnv.New32a()
+		for n := from; n < to; n++ {
+			h.Reset()
+			h.Write([]byte(c[n].Repository))
+			shards[n] = int32(h.Sum32() % uint32(workers))
+		}
+	})
+
+	offsets := make([]int, workers+1)
+	for _, s := range shards {
+		offsets[s+1]++
+	}
+	for w := 0; w < workers; w++ {
+		offsets[w+1] += offsets[w]
+	}
+	next := slices.Clone(offsets[:workers])
+	order := make([]int, len(c))
+	for n, s := range shards {
+		order[next[s]] = n
+		next[s]++
+	}
+	index := make([][]int, workers)
+	for w := range index {
+		index[w] = order[offsets[w]:offsets[w+1]]
+	}
+	return index
+}
+
+// GroupByRepositoryParallel shards commits by repository across workers, each worker grouping its shard.
+// Commits keep their input order within a repository so the result matches GroupByRepository.
+func GroupByRepositoryParallel(c []types.Commit, workers int, debug bool) map[string]types.Repository {
+	if workers <= 1 {
+		return GroupByRepository(c, debug)
+	}
+	Debugger(fmt.Sprintf("group commits by repository with %d workers", workers), debug)
+
+	// first pass: assign each commit to the shard owning its repository
+	shards := shardIndex(c, workers)
+
+	// second pass: every worker accumulates the repositories of its shard
+	accumulators := make([]map[string][]types.Commit, workers)
+	chunks(workers, workers, func(w, _, _ int) {
+		acc := make(map[string][]types.Commit)
+		for _, n := range shards[w] {
+			acc[c[n].Repository] = append(acc[c[n].Repository], c[n])
+		}
+		accumulators[w] = acc
+	})
+
+	// shards hold disjoint repositories so merging can't conflict
+	repoMap := make(map[string]types.Repository)
+	for _, acc := range accumulators {
+		for name, commits := range acc {
+			repoMap[name] = types.Repository{Repository: name, Score: 1, Commits: commits}
+		}
+	}
+	return repoMap
+}
+
+// AggregateByRepository builds the aggregate of every repository in a single pass,
+// sharding repositories across workers like GroupByRepositoryParallel.
+// A non zero precision counts distinct users approximately, see types.NewAggregate.
+func AggregateByRepository(c []types.Commit, workers int, precision uint8, debug bool) map[string]*types.Aggregate {
+	Debugger(fmt.Sprintf("aggregate commits by repository with %d workers", workers), debug)
+	workers = max(workers, 1)
+	shards := shardIndex(c, workers)
+	accumulators := make([]map[string]*types.Aggregate, workers)
+	chunks(workers, workers, func(w, _, _ int) {
+		acc := make(map[string]*types.Aggregate)
+		for _, n := range shards[w] {
+			a, ok := acc[c[n].Repository]
+			if !ok {
+				a = types.NewAggregate(precision)
+				acc[c[n].Repository] = a
+			}
+			a.Add(c[n])
+		}
+		accumulators[w] = acc
+	})
+
+	aggregates := make(map[string]*types.Aggregate)
+	for _, acc := range accumulators {
+		for name, a := range acc {
+			aggregates[name] = a
+		}
+	}
+	return aggregates
+}
+
+// ScoreRepositories scores repositories concurrently and returns them ordered by name,
+// so sorting by score afterwards gives a deterministic ranking.
+func ScoreRepositories(repoMap map[string]types.Repository, workers int, score func(r *types.Repository), debug bool) []types.Repository {
+	Debugger(fmt.Sprintf("scoring %d repositories with %d workers", len(repoMap), workers), debug)
+	repos := make([]types.Repository, 0, len(repoMap))
+	for _, r := range repoMap {
+		repos = append(repos, r)
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Repository < repos[j].Repository })
+	chunks(len(repos), max(workers, 1), func(_, from, to int) {
+		for n := from; n < to; n++ {
+			score(&repos[n])
+		}
+	})
+	return repos
+}
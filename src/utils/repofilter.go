@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RepositoryFilter ...
+type RepositoryFilter struct {
+	Include, Exclude []string
+	include, exclude []func(name string) bool
+}
+
+// compilePattern matches a repository name against a /regular expression/ or else a glob such as payments-*.
+func compilePattern(pattern string) (func(name string) bool, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid repository pattern %s: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid repository pattern %s: %w", pattern, err)
+	}
+	return func(name string) bool {
+		ok, _ := path.Match(pattern, name)
+		return ok
+	}, nil
+}
+
+// NewRepositoryFilter keeps the repositories matching any include pattern, all of them without one,
+// unless they match an exclude pattern.
+func NewRepositoryFilter(include, exclude []string) (RepositoryFilter, error) {
+	f := RepositoryFilter{Include: include, Exclude: exclude}
+	for _, list := range []struct {
+		patterns []string
+		into     *[]func(string) bool
+	}{{include, &f.include}, {exclude, &f.exclude}} {
+		for _, p := range list.patterns {
+			match, err := compilePattern(p)
+			if err != nil {
+				return f, err
+			}
+			*list.into = append(*list.into, match)
+		}
+	}
+	return f, nil
+}
+
+// Empty reports whether the filter keeps every repository.
+func (f RepositoryFilter) Empty() bool {
+	return len(f.include) == 0 && len(f.exclude) == 0
+}
+
+// Keep reports whether a repository passes the filter.
+func (f RepositoryFilter) Keep(name string) bool {
+	for _, match := range f.exclude {
+		if match(name) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, match := range f.include {
+		if match(name) {
+			return true
+		}
+	}
+	return false
+}
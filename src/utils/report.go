@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+
+	"github.com/FliCrz/blipper/src/types"
+)
+
+var htmlReport = template.Must(template.New("report").Funcs(templateFuncs(template.FuncMap{
+	"color": func(n int) string { return Colors[n%len(Colors)] },
+	"sparkline": func(buckets []types.Bucket) template.HTML {
+		values := make([]int64, len(buckets))
+		for n, b := range buckets {
+			values[n] = b.Commits
+		}
+		return template.HTML(Sparkline(values, 120, 24))
+	},
+	"width": func(points, score int64) string {
+		if score <= 0 || points <= 0 {
+			return "0"
+		}
+		return fmt.Sprintf("%.2f", float64(points)*100/float64(score))
+	},
+})).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Repository Activity Score</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 1100px; color: #1a202c; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { text-align: left; padding: 6px 10px; border-bottom: 1px solid #e2e8f0; vertical-align: top; }
+th { background: #f7fafc; }
+td.number { text-align: right; font-variant-numeric: tabular-nums; }
+.bar { display: flex; height: 10px; width: 220px; background: #edf2f7; }
+.bar span { display: block; height: 100%; }
+.legend span { display: inline-block; width: 10px; height: 10px; margin: 0 4px 0 12px; }
+small { color: #718096; }
+</style>
+</head>
+<body>
+<h1>Repository Activity Score</h1>
+<p><small>Generated {{date .Generated .Timezone}} ({{.Timezone}}) from {{.Filename}}</small></p>
+
+<h2>Dataset</h2>
+<table>
+<tr><th>Window</th><td>{{date .Summary.First .Timezone}} to {{date .Summary.Last .Timezone}}, {{.NumberOfDays}} days analyzed</td></tr>
+<tr><th>Commits</th><td>{{.Summary.Commits}} ({{.Summary.CommitsPerActiveDay | printf "%.1f"}} per active day over {{.Summary.ActiveDays}} days)</td></tr>
+<tr><th>Users</th><td>{{.Summary.Users}} known, {{percent .Summary.UnknownShare}} of commits by unknown authors</td></tr>
+<tr><th>Changes</th><td>{{.Summary.Files}} files, {{.Summary.Additions}} additions, {{.Summary.Deletions}} deletions</td></tr>
+</table>
+
+<h2>Algorithm</h2>
+<table>
+<tr><th>Metric</th><th>Weight</th></tr>
+{{range .Algorithm}}<tr><td>{{.Metric}}</td><td class="number">{{.Weight}}</td></tr>
+{{end}}</table>
+
+<h2>Top {{len .Repositories}} repositories</h2>
+{{with .Repositories}}<p class="legend">{{range $n, $p := (index . 0).Breakdown}}<span style="background: {{color $n}}"></span>{{$p.Metric}}{{end}}</p>{{end}}
+<table>
+<tr><th>#</th><th>Repository</th><th>Score</th><th>Breakdown</th><th>Weekly commits</th><th>Top contributors</th></tr>
+{{range .Repositories}}{{$score := .Score}}<tr>
+<td class="number">{{.Rank}}</td>
+<td>{{.Repository}}</td>
+<td class="number">{{.Score}}</td>
+<td><div class="bar">{{range $n, $p := .Breakdown}}<span title="{{$p.Metric}}: {{$p.Value}} x {{$p.Weight}} = {{$p.Points}}" style="width: {{width $p.Points $score}}%; background: {{color $n}}"></span>{{end}}</div>
+<small>{{range $n, $p := .Breakdown}}{{if $n}}, {{end}}{{$p.Metric}} {{$p.Points}}{{end}}</small></td>
+<td>{{sparkline .Weekly}}</td>
+<td>{{range $n, $c := .TopContributors}}{{if $n}}, {{end}}{{$c.User}} ({{$c.Commits}}){{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// Colors used for the score parts of charts and reports.
+var Colors = []string{"#2b6cb0", "#dd6b20", "#38a169", "#d53f8c", "#805ad5", "#d69e2e", "#319795", "#e53e3e"}
+
+// WriteHTMLReport writes a self-contained index.html into dir and returns its path.
+func WriteHTMLReport(dir string, report types.Report, debug bool) string {
+	path := filepath.Join(dir, "index.html")
+	Debugger(fmt.Sprintf("writing report: %s", path), debug)
+	ErrorLogger(os.MkdirAll(dir, 0o755))
+	f, err := os.Create(path)
+	ErrorLogger(err)
+	defer f.Close()
+	ErrorLogger(htmlReport.Execute(f, report))
+	return path
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/FliCrz/blipper/src/types"
 )
@@ -29,6 +32,12 @@ func ParseInt(s string) (i int64) {
 	return i
 }
 
+func ParseFloat(s string) (f float64) {
+	f, err := strconv.ParseFloat(s, 64)
+	ErrorLogger(err)
+	return f
+}
+
 func help(f string, n int64) {
 	fmt.Printf(`
 	
@@ -37,12 +46,62 @@ Welcome to blipper
 Usage:
     blipper
   or
-    blipper [-f] [-n] [-t] [-d] [-v] [-h] 
+    blipper [command] [-f] [-n] [-t] [--teams] [-d] [-v] [-h] 
+
+Commands:
+  rank                    (default) rank repositories by activity score
+  timeseries              activity buckets: [--repo] [-i, --interval day|week|month (default: week)]
+  trend                   repositories sorted by momentum, rising first: [--window weeks (default: 4)]
+  diff                    ranking changes: --base old.csv --head new.csv, --base-snapshot id --head-snapshot id,
+                          or --split date|timestamp of -f
+  snapshots               list the snapshots saved with rank --save
+  history                 score and rank of a repository across snapshots: --repo
+  alerts                  activity drops and spikes in the last bucket: [-i, --interval] [--baseline buckets (default: 4)]
+                          [--threshold zscore (default: 3)] [--thresholds file] [--all] [--out file] [--webhook url]
+  risk                    contributor concentration per repository, most at risk first: [--by]
+  cohorts                 contributor retention per repository, or weekly cohorts of --repo: [--contributors]
+                          [--inactive days (default: 28)]
+  stats                   summary statistics of the dataset and of each repository
+  sizes                   commit size distribution per repository: [--sizes] [--size-files] [--size-lines]
+  activity                weekday by hour heatmap of the dataset, a repository or a contributor: [--repo] [--user]
+  metrics                 Prometheus metrics on http://--addr/metrics (default: :9090), or written to a --textfile
+  report                  report of the top repositories: --html dir and/or --markdown [--template file] [--out file]
+                          [--top (default: 10)]
+  chart                   SVG chart written to -o file (default: stdout): --type breakdown (default) of the --top repositories,
+                          activity (weekly commits of the --top repositories or of --repo), concentration (authors of --repo)
+  tui                     interactive ranking: adjust weights and window, sort by metric, drill into a repository
 
   -f, --filename          CSV filename to read from (default: %s)
   -n, --numberOfDays      number of days being analyzed (default: %d, minimum 1)
   -t, --target            (optional) target to where to apply score if not set it will use our score algorythm
-						  options: timestamp, files, additions, deletions, users, commits
+						  options: timestamp, files, additions, deletions, users, commits, distinctUsers,
+						  externalTeams, externalShare, externalRetention (these require --teams),
+						  momentum (see --window), busFactor, gini, topShare (see --by), flow (see --sizes)
+						  or the name of a --metric
+  --metric                (optional, repeatable) named metric expression, e.g. --metric 'churn = sum(additions + deletions)'
+  --metrics               (optional) file of named metric expressions, one name = expression per line
+  --teams                 (optional) CSV file mapping users to teams, with a user,team header, for inner-source targets
+  --by                    contributions used by concentration metrics: commits, churn (default: commits)
+  --sizes                 commit size thresholds: default, quantiles (default: default)
+  --size-files            medium,large,huge lower bounds of files changed (default: 5,20,100)
+  --size-lines            medium,large,huge lower bounds of lines changed (default: 50,250,1000)
+  --timezone              IANA timezone of day, week and month boundaries, dates and displayed times (default: UTC)
+  --since                 (optional) only commits from this date (YYYY-MM-DD) or unix timestamp on
+  --until                 (optional) only commits before this date (YYYY-MM-DD) or unix timestamp
+  --include-repo          (optional, repeatable) only repositories matching a glob (payments-*) or a /regular expression/
+  --exclude-repo          (optional, repeatable) never load repositories matching a glob or a /regular expression/
+  --include-file          (optional) allowlist file of repository patterns, one per line
+  --exclude-file          (optional) denylist file of repository patterns, one per line
+  --where                 (optional, repeatable) only commits matching the filter, e.g. --where 'user != "unknown" and files < 500'
+  --hours-weight          (optional) 0 to 1, lowers scores by the weighted share of weekend and off-hours commits
+  --workers               goroutines grouping and scoring repositories (default: number of CPUs)
+  --distinct              distinct users counting: exact, approx (default: exact)
+  --precision             (approx) HyperLogLog precision from 4 to 16 (default: 12, about 1.6%% standard error)
+  --incremental           (rank) state file, only commits newer than the state are ingested before ranking
+  --watch                 (rank) rerun the ranking whenever the input file changes: [--poll seconds (default: 2)]
+  --save                  (rank) save the ranking as a snapshot in the store
+  --store                 snapshot store file (default: snapshots.jsonl)
+  --format                output format: table, json, csv (default: table)
   -d, --debug             logging (default: false)
   -v, --version           current version
   -h, --help              this help message
@@ -78,6 +137,51 @@ func ParseArgs(filepath, scoringFilter, version string, numberOfDays int64, debu
 	return filepath, numberOfDays, scoringFilter, debug
 }
 
+// GetCommand returns the subcommand given as first argument, "rank" if none.
+func GetCommand() string {
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		return os.Args[1]
+	}
+	return "rank"
+}
+
+// GetArg returns the value following any of the given flags, or def if none is present.
+func GetArg(def string, names ...string) string {
+	for n := range os.Args {
+		if n > 0 && n+1 < len(os.Args) {
+			for _, name := range names {
+				if os.Args[n] == name {
+					def = os.Args[n+1]
+				}
+			}
+		}
+	}
+	return def
+}
+
+// GetArgs returns the values of every occurrence of the given flags, in order.
+func GetArgs(names ...string) []string {
+	var values []string
+	for n := range os.Args {
+		if n > 0 && n+1 < len(os.Args) && slices.Contains(names, os.Args[n]) {
+			values = append(values, os.Args[n+1])
+		}
+	}
+	return values
+}
+
+// HasArg reports whether any of the given flags is present.
+func HasArg(names ...string) bool {
+	for n := range os.Args {
+		for _, name := range names {
+			if n > 0 && os.Args[n] == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func ReadCsvToCommits(filepath string, debug bool) [][]string {
 	Debugger(fmt.Sprintf("reading file: %s", filepath), debug)
 	f, err := os.Open(filepath)
@@ -89,6 +193,42 @@ func ReadCsvToCommits(filepath string, debug bool) [][]string {
 	return raw
 }
 
+// ReadTeams reads a user,team CSV file into a map of user to team, its first row being the header.
+func ReadTeams(filepath string, debug bool) map[string]string {
+	teams := make(map[string]string)
+	for n, i := range ReadCsvToCommits(filepath, debug) {
+		if n > 0 {
+			teams[i[0]] = i[1]
+		}
+	}
+	return teams
+}
+
+// ReadLines reads the lines of a file, skipping blank lines and # comments.
+func ReadLines(filepath string, debug bool) []string {
+	Debugger(fmt.Sprintf("reading lines: %s", filepath), debug)
+	b, err := os.ReadFile(filepath)
+	ErrorLogger(err)
+	var lines []string
+	for _, line := range strings.Split(string(b), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// ReadThresholds reads a repository,threshold CSV file.
+func ReadThresholds(filepath string, debug bool) map[string]float64 {
+	thresholds := make(map[string]float64)
+	for n, i := range ReadCsvToCommits(filepath, debug) {
+		if n > 0 {
+			thresholds[i[0]] = ParseFloat(i[1])
+		}
+	}
+	return thresholds
+}
+
 func SortByScore(repos []types.Repository) {
 	sort.SliceStable(repos, func(i int, j int) bool {
 		return repos[i].Score > repos[j].Score
@@ -116,16 +256,73 @@ func SortCommitsDecreasing(commits []types.Commit, filter string, debug bool) []
 	return commits
 }
 
+// TimeRange returns the first and last commit timestamps.
+func TimeRange(c []types.Commit) (first, last int64) {
+	for n, i := range c {
+		if n == 0 || i.Timestamp < first {
+			first = i.Timestamp
+		}
+		if n == 0 || i.Timestamp > last {
+			last = i.Timestamp
+		}
+	}
+	return first, last
+}
+
+// ParseTime parses a unix timestamp or a YYYY-MM-DD date in the given location.
+func ParseTime(s string, loc *time.Location) int64 {
+	if t, err := time.ParseInLocation(time.DateOnly, s, loc); err == nil {
+		return t.Unix()
+	}
+	return ParseInt(s)
+}
+
+// DiffRankings compares two rankings sorted by score, entries follow the head ranking
+// and repositories missing from it are appended as dropped.
+func DiffRankings(base, head []types.Repository) (changes []types.RankChange) {
+	baseIndex := make(map[string]int)
+	for n, r := range base {
+		baseIndex[r.Repository] = n
+	}
+	headIndex := make(map[string]bool)
+	for n, r := range head {
+		headIndex[r.Repository] = true
+		c := types.RankChange{Repository: r.Repository, Status: "new", HeadRank: int64(n + 1), HeadScore: r.Score, Delta: r.Score}
+		if b, ok := baseIndex[r.Repository]; ok {
+			c.BaseRank = int64(b + 1)
+			c.BaseScore = base[b].Score
+			c.Movement = c.BaseRank - c.HeadRank
+			c.Delta = c.HeadScore - c.BaseScore
+			switch {
+			case c.Movement > 0:
+				c.Status = "up"
+			case c.Movement < 0:
+				c.Status = "down"
+			default:
+				c.Status = "same"
+			}
+		}
+		changes = append(changes, c)
+	}
+	for n, r := range base {
+		if !headIndex[r.Repository] {
+			changes = append(changes, types.RankChange{
+				Repository: r.Repository, Status: "dropped", BaseRank: int64(n + 1), BaseScore: r.Score, Delta: -r.Score,
+			})
+		}
+	}
+	return changes
+}
+
 func GroupByRepository(c []types.Commit, debug bool) map[string]types.Repository {
 	Debugger("group commits by repository", debug)
-	repoMap := make(map[string]types.Repository)
+	grouped := make(map[string][]types.Commit)
 	for _, i := range c {
-		repo := types.Repository{
-			Repository: i.Repository,
-			Score:      1,
-			Commits:    append(repoMap[i.Repository].Commits, i),
-		}
-		repoMap[i.Repository] = repo
+		grouped[i.Repository] = append(grouped[i.Repository], i)
+	}
+	repoMap := make(map[string]types.Repository, len(grouped))
+	for name, commits := range grouped {
+		repoMap[name] = types.Repository{Repository: name, Score: 1, Commits: commits}
 	}
 	return repoMap
 }
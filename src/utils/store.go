@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/FliCrz/blipper/src/types"
+)
+
+// ProfileHash returns a short stable hash of the scoring options so snapshots
+// produced with the same options can be told apart from others.
+func ProfileHash(options ...string) string {
+	h := sha256.Sum256([]byte(strings.Join(options, "\x00")))
+	return fmt.Sprintf("%x", h[:6])
+}
+
+// ReadSnapshots reads every snapshot of a JSON-lines store, a missing store has none.
+func ReadSnapshots(path string, debug bool) (snapshots []types.Snapshot) {
+	Debugger(fmt.Sprintf("reading snapshots: %s", path), debug)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	ErrorLogger(err)
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 64*1024*1024)
+	for s.Scan() {
+		var snapshot types.Snapshot
+		ErrorLogger(json.Unmarshal(s.Bytes(), &snapshot))
+		snapshots = append(snapshots, snapshot)
+	}
+	ErrorLogger(s.Err())
+	return snapshots
+}
+
+// AppendSnapshot assigns the next id to the snapshot and appends it to the store.
+func AppendSnapshot(path string, snapshot types.Snapshot, debug bool) types.Snapshot {
+	Debugger(fmt.Sprintf("saving snapshot: %s", path), debug)
+	snapshot.ID = int64(len(ReadSnapshots(path, debug)) + 1)
+	repos := make([]types.Repository, len(snapshot.Repositories))
+	for n, r := range snapshot.Repositories {
+		repos[n] = types.Repository{Repository: r.Repository, Score: r.Score}
+	}
+	snapshot.Repositories = repos
+	b, err := json.Marshal(snapshot)
+	ErrorLogger(err)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	ErrorLogger(err)
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	ErrorLogger(err)
+	return snapshot
+}
+
+// FindSnapshot returns the snapshot with the given id.
+func FindSnapshot(snapshots []types.Snapshot, id int64) types.Snapshot {
+	for _, s := range snapshots {
+		if s.ID == id {
+			return s
+		}
+	}
+	ErrorLogger(fmt.Errorf("snapshot not found: %d", id))
+	return types.Snapshot{}
+}
+
+// ReadState reads the incremental state, a missing file gives an empty state.
+func ReadState(path string, debug bool) types.State {
+	Debugger(fmt.Sprintf("reading state: %s", path), debug)
+	state := types.State{Repositories: make(map[string]*types.Aggregate)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state
+	}
+	ErrorLogger(err)
+	ErrorLogger(json.Unmarshal(b, &state))
+	return state
+}
+
+// WriteState replaces the incremental state file, through a temporary file so an interrupted run keeps the previous state.
+func WriteState(path string, state types.State, debug bool) {
+	tmp := path + ".tmp"
+	WriteJSON(tmp, state, debug)
+	ErrorLogger(os.Rename(tmp, path))
+}
+
+// Ingest merges the commits newer than the state into it, returning how many were ingested.
+// The options profile must match the one the state was built with, aggregates of other filters
+// or distinct counting would not merge.
+func Ingest(state *types.State, c []types.Commit, options string, workers int, precision uint8, debug bool) int {
+	if len(state.Repositories) > 0 && state.Options != options {
+		ErrorLogger(fmt.Errorf("state built with other ingest options: keep the same --distinct, --precision, --since, --until, repository lists and --where, or start a new state file"))
+	}
+	state.Options = options
+	var fresh []types.Commit
+	for _, i := range c {
+		if len(state.Repositories) == 0 || i.Timestamp > state.Last {
+			fresh = append(fresh, i)
+		}
+	}
+	Debugger(fmt.Sprintf("ingesting %d new commits", len(fresh)), debug)
+	if len(fresh) == 0 {
+		return 0
+	}
+	first, last := TimeRange(fresh)
+	if len(state.Repositories) == 0 || first < state.First {
+		state.First = first
+	}
+	state.Last = max(state.Last, last)
+	for name, a := range AggregateByRepository(fresh, workers, precision, debug) {
+		if s, ok := state.Repositories[name]; ok {
+			s.Merge(a)
+		} else {
+			state.Repositories[name] = a
+		}
+	}
+	return len(fresh)
+}
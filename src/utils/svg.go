@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Sparkline renders values as a small inline SVG polyline scaled to the highest value.
+func Sparkline(values []int64, width, height int) string {
+	var highest int64
+	for _, v := range values {
+		highest = max(highest, v)
+	}
+	points := make([]string, len(values))
+	for n, v := range values {
+		x := 0.0
+		if len(values) > 1 {
+			x = float64(n) * float64(width) / float64(len(values)-1)
+		}
+		y := float64(height)
+		if highest > 0 {
+			y -= float64(v) * float64(height-2) / float64(highest)
+		}
+		points[n] = fmt.Sprintf("%.1f,%.1f", x, y-1)
+	}
+	return fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+
+			`<polyline fill="none" stroke="#2b6cb0" stroke-width="1.5" points="%s"/></svg>`,
+		width, height, width, height, strings.Join(points, " "))
+}
+
+// Series ...
+type Series struct {
+	Name   string
+	Values []int64
+}
+
+// Slice ...
+type Slice struct {
+	Label string
+	Value int64
+}
+
+const (
+	chartWidth  = 800
+	chartMargin = 40
+	legendWidth = 160
+	barHeight   = 22
+)
+
+var svgEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+func svgOpen(b *strings.Builder, width, height int, title string) {
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif" font-size="12">`+"\n", width, height, width, height)
+	fmt.Fprintf(b, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	fmt.Fprintf(b, `<text x="%d" y="24" font-size="16" font-weight="bold">%s</text>`+"\n", chartMargin, svgEscaper.Replace(title))
+}
+
+func svgLegend(b *strings.Builder, x, y int, names []string) {
+	for n, name := range names {
+		fmt.Fprintf(b, `<rect x="%d" y="%d" width="10" height="10" fill="%s"/>`, x, y+n*18, Colors[n%len(Colors)])
+		fmt.Fprintf(b, `<text x="%d" y="%d">%s</text>`+"\n", x+16, y+n*18+10, svgEscaper.Replace(name))
+	}
+}
+
+// StackedBarChart renders one horizontal bar per label, stacking its parts in the order of names.
+// Negative parts are left out of the bars.
+func StackedBarChart(title string, labels, names []string, parts [][]int64) string {
+	height := 2*chartMargin + len(labels)*(barHeight+8)
+	plot := chartWidth - 2*chartMargin - legendWidth - 80 // room for labels on the left
+	var highest int64
+	for _, row := range parts {
+		var total int64
+		for _, v := range row {
+			total += max(v, 0)
+		}
+		highest = max(highest, total)
+	}
+
+	var b strings.Builder
+	svgOpen(&b, chartWidth, height, title)
+	for n, label := range labels {
+		y := chartMargin + n*(barHeight+8)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", chartMargin+75, y+barHeight/2+4, svgEscaper.Replace(label))
+		x := float64(chartMargin + 80)
+		for i, v := range parts[n] {
+			if v <= 0 || highest == 0 {
+				continue
+			}
+			w := float64(v) * float64(plot) / float64(highest)
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%d" width="%.1f" height="%d" fill="%s"><title>%s: %d</title></rect>`+"\n",
+				x, y, w, barHeight, Colors[i%len(Colors)], svgEscaper.Replace(names[i]), v)
+			x += w
+		}
+	}
+	svgLegend(&b, chartWidth-legendWidth, chartMargin, names)
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// LineChart renders series sharing the same x labels, labelling the first, middle and last points.
+func LineChart(title string, xLabels []string, series []Series) string {
+	height := max(320, 2*chartMargin+len(series)*18) // room for the legend
+	plotWidth := chartWidth - 2*chartMargin - legendWidth
+	plotHeight := height - 3*chartMargin
+	var highest int64
+	for _, s := range series {
+		for _, v := range s.Values {
+			highest = max(highest, v)
+		}
+	}
+	x := func(n int) float64 {
+		if len(xLabels) < 2 {
+			return chartMargin
+		}
+		return chartMargin + float64(n)*float64(plotWidth)/float64(len(xLabels)-1)
+	}
+	y := func(v int64) float64 {
+		if highest == 0 {
+			return float64(chartMargin + plotHeight)
+		}
+		return float64(chartMargin+plotHeight) - float64(v)*float64(plotHeight)/float64(highest)
+	}
+
+	var b strings.Builder
+	svgOpen(&b, chartWidth, height, title)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#a0aec0"/>`+"\n",
+		chartMargin, chartMargin+plotHeight, chartMargin+plotWidth, chartMargin+plotHeight)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#a0aec0"/>`+"\n",
+		chartMargin, chartMargin, chartMargin, chartMargin+plotHeight)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%d</text>`+"\n", chartMargin-4, chartMargin+4, highest)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">0</text>`+"\n", chartMargin-4, chartMargin+plotHeight)
+	if len(xLabels) > 0 {
+		for _, n := range []int{0, len(xLabels) / 2, len(xLabels) - 1} {
+			fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", x(n), chartMargin+plotHeight+18, svgEscaper.Replace(xLabels[n]))
+		}
+	}
+	names := make([]string, len(series))
+	for i, s := range series {
+		names[i] = s.Name
+		points := make([]string, len(s.Values))
+		for n, v := range s.Values {
+			points[n] = fmt.Sprintf("%.1f,%.1f", x(n), y(v))
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`+"\n", Colors[i%len(Colors)], strings.Join(points, " "))
+	}
+	svgLegend(&b, chartWidth-legendWidth, chartMargin, names)
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// PieChart renders the share of each slice, a single slice being drawn as a full circle.
+func PieChart(title string, slices []Slice) string {
+	height := 320
+	cx, cy, r := float64(chartMargin+130), float64(chartMargin+140), 120.0
+	var total int64
+	for _, s := range slices {
+		total += max(s.Value, 0)
+	}
+
+	var b strings.Builder
+	svgOpen(&b, chartWidth/2+legendWidth, height, title)
+	angle := -math.Pi / 2
+	names := make([]string, len(slices))
+	for n, s := range slices {
+		share := 0.0
+		if total > 0 {
+			share = float64(max(s.Value, 0)) / float64(total)
+		}
+		names[n] = fmt.Sprintf("%s %.0f%%", s.Label, share*100)
+		color := Colors[n%len(Colors)]
+		switch {
+		case share >= 1:
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s"/>`+"\n", cx, cy, r, color)
+		case share > 0:
+			end := angle + share*2*math.Pi
+			large := 0
+			if share > 0.5 {
+				large = 1
+			}
+			fmt.Fprintf(&b, `<path d="M %.1f %.1f L %.1f %.1f A %.1f %.1f 0 %d 1 %.1f %.1f Z" fill="%s"><title>%s: %d</title></path>`+"\n",
+				cx, cy, cx+r*math.Cos(angle), cy+r*math.Sin(angle), r, r, large, cx+r*math.Cos(end), cy+r*math.Sin(end),
+				color, svgEscaper.Replace(s.Label), s.Value)
+			angle = end
+		}
+	}
+	svgLegend(&b, int(cx+r)+30, chartMargin, names)
+	b.WriteString("</svg>\n")
+	return b.String()
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Terminal escape sequences of the interactive views.
+const (
+	AltScreen   = "\x1b[?1049h\x1b[?25l"
+	MainScreen  = "\x1b[?25h\x1b[?1049l"
+	ClearScreen = "\x1b[H\x1b[2J"
+	Reverse     = "\x1b[7m"
+	Bold        = "\x1b[1m"
+	Reset       = "\x1b[0m"
+)
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// RawTerminal switches the terminal to read keys one by one without echo nor signals, Ctrl+C being read
+// as a key so the caller quits through its own cleanup. The returned function restores the previous settings.
+func RawTerminal(debug bool) (restore func(), err error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("an interactive terminal is required: %w", err)
+	}
+	if _, err := stty("-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		return nil, err
+	}
+	Debugger(fmt.Sprintf("terminal settings saved: %s", saved), debug)
+	return func() {
+		if _, err := stty(saved); err != nil {
+			Debugger(fmt.Sprintf("failed to restore the terminal: %s", err), debug)
+		}
+	}, nil
+}
+
+// TerminalSize returns the rows and columns of the terminal, 24x80 when unknown.
+func TerminalSize() (rows, cols int) {
+	out, err := stty("size")
+	if err != nil {
+		return 24, 80
+	}
+	if _, err := fmt.Sscan(out, &rows, &cols); err != nil || rows == 0 || cols == 0 {
+		return 24, 80
+	}
+	return rows, cols
+}
+
+// ReadKey reads a key press, naming the special keys: up, down, left, right, pgup, pgdown, home, end,
+// enter, esc, tab, backspace and ctrl+c. Other keys are returned as typed.
+func ReadKey(r *bufio.Reader) (string, error) {
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	switch c {
+	case '\r', '\n':
+		return "enter", nil
+	case '\t':
+		return "tab", nil
+	case 127, '\b':
+		return "backspace", nil
+	case 0x03:
+		return "ctrl+c", nil
+	case 0x1b:
+		if r.Buffered() == 0 {
+			return "esc", nil
+		}
+		if next, _ := r.Peek(1); next[0] != '[' && next[0] != 'O' {
+			return "esc", nil
+		}
+		r.ReadByte()
+		var seq []byte
+		for r.Buffered() > 0 {
+			b, _ := r.ReadByte()
+			seq = append(seq, b)
+			if b >= 0x40 && b <= 0x7e {
+				break
+			}
+		}
+		switch string(seq) {
+		case "A":
+			return "up", nil
+		case "B":
+			return "down", nil
+		case "C":
+			return "right", nil
+		case "D":
+			return "left", nil
+		case "H", "1~":
+			return "home", nil
+		case "F", "4~":
+			return "end", nil
+		case "5~":
+			return "pgup", nil
+		case "6~":
+			return "pgdown", nil
+		}
+		return "esc", nil
+	}
+	return string(c), nil
+}
+
+var blocks = []rune("▁▂▃▄▅▆▇█")
+
+// TextSparkline renders values as block characters scaled to the highest value,
+// any activity being shown above the lowest block.
+func TextSparkline(values []int64) string {
+	var highest int64
+	for _, v := range values {
+		highest = max(highest, v)
+	}
+	line := make([]rune, len(values))
+	for n, v := range values {
+		line[n] = blocks[0]
+		if highest > 0 && v > 0 {
+			line[n] = blocks[max(v*int64(len(blocks)-1)/highest, 1)]
+		}
+	}
+	return string(line)
+}
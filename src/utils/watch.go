@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// WatchFile polls a file every interval and calls changed whenever its size or modification time differ
+// from the previous poll, until stop is closed. A file being rewritten is only reported once it is stable for a poll.
+func WatchFile(path string, interval time.Duration, stop <-chan struct{}, changed func(), debug bool) {
+	Debugger(fmt.Sprintf("watching file: %s", path), debug)
+	stat := func() (int64, time.Time) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return -1, time.Time{}
+		}
+		return info.Size(), info.ModTime()
+	}
+	size, modTime := stat()
+	pending := false
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s, m := stat()
+			switch {
+			case s != size || !m.Equal(modTime):
+				Debugger(fmt.Sprintf("file changed: %s", path), debug)
+				size, modTime, pending = s, m, true
+			case pending && s >= 0:
+				pending = false
+				changed()
+			}
+		}
+	}
+}
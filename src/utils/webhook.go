@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhookClient gives up on webhooks that don't respond so a stalled endpoint doesn't hang the job.
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// PostJSON sends v as a JSON body to url, failing on non 2xx responses.
+func PostJSON(url string, v any, debug bool) {
+	Debugger(fmt.Sprintf("posting to webhook: %s", url), debug)
+	b, err := json.Marshal(v)
+	ErrorLogger(err)
+	resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(b))
+	ErrorLogger(err)
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		ErrorLogger(fmt.Errorf("webhook %s responded %s", url, resp.Status))
+	}
+}
@@ -0,0 +1,53 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FliCrz/blipper/src/utils"
+)
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "commits.csv")
+	if err := os.WriteFile(path, []byte("timestamp\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := make(chan struct{}, 10)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		utils.WatchFile(path, 10*time.Millisecond, stop, func() { changed <- struct{}{} }, false)
+		close(done)
+	}()
+
+	select {
+	case <-changed:
+		t.Fatal("WatchFile() reported a change before the file changed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := os.WriteFile(path, []byte("timestamp\n1610969774\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("WatchFile() didn't report the change")
+	}
+
+	select {
+	case <-changed:
+		t.Fatal("WatchFile() reported the same change twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("WatchFile() didn't stop")
+	}
+}